@@ -0,0 +1,155 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// ChunkFunc processes a batch of records. db is the transaction of the batch when chunking with ChunkTx, otherwise it is the db passed to Chunk
+type ChunkFunc[T any] func(db QuerierExecuterContext, batch []T) error
+
+// Chunk walks the rows of T matched by the where condition in batches of size, calling fn with each batch. See ChunkContext for details
+func Chunk[T any](db QuerierExecuter, v *[]T, size int, where string, args []any, fn ChunkFunc[T]) error {
+	return ChunkContext(context.Background(), querierExecuterContext(db), v, size, where, args, fn)
+}
+
+// ChunkContext walks the rows of T matched by the where condition in batches of size, calling fn with each batch.
+// Rows are ordered by primary key and every batch starts after the last key of the previous one,
+// so rows can be modified by fn without shifting the batches which follow.
+// The where condition is given without the WHERE keyword and may be empty.
+// v holds the current batch and its capacity is reused between batches.
+// Walking stops at the first error returned by fn.
+func ChunkContext[T any](ctx context.Context, db QuerierExecuterContext, v *[]T, size int, where string, args []any, fn ChunkFunc[T]) error {
+	return chunk(ctx, db, v, size, where, args, func(sql string, args []any) error {
+		if err := ListContext(ctx, db, v, sql, args...); err != nil {
+			return err
+		}
+
+		if len(*v) == 0 {
+			return nil
+		}
+
+		return fn(db, *v)
+	})
+}
+
+// ChunkTx walks the rows of T like Chunk, reading and processing each batch within its own transaction. See ChunkTxContext for details
+func ChunkTx[T any](db DB, v *[]T, size int, where string, args []any, fn ChunkFunc[T]) error {
+	return ChunkTxContext(context.Background(), dbContext(db), v, size, where, args, fn)
+}
+
+// ChunkTxContext walks the rows of T like ChunkContext, reading and processing each batch within its own transaction.
+// A transaction is committed once fn returns nil for its batch and rolled back otherwise,
+// leaving the batches before it committed. ErrTxNotSupported is returned when db is itself a transaction.
+func ChunkTxContext[T any](ctx context.Context, db DBContext, v *[]T, size int, where string, args []any, fn ChunkFunc[T]) error {
+	return chunk(ctx, db, v, size, where, args, func(sql string, args []any) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		txdb := bindTx(db, tx)
+		return runTx(func() error {
+			if err := ListContext(ctx, txdb, v, sql, args...); err != nil {
+				return err
+			}
+
+			if len(*v) == 0 {
+				return nil
+			}
+
+			return fn(txdb, *v)
+		}, tx.Commit, tx.Rollback)
+	})
+}
+
+// chunk builds the keyset query of each batch for the dialect of db and calls batch with it until a batch holds fewer than size rows
+func chunk[T any](ctx context.Context, db any, v *[]T, size int, where string, args []any, batch func(sql string, args []any) error) error {
+	if v == nil || size < 1 {
+		return ErrInvalidType
+	}
+
+	// the where condition is bound before the key of the previous batch is appended to its arguments
+	where, args, err := bindArgs(db, where, args)
+	if err != nil {
+		return err
+	}
+
+	mapping, _, err := schema.GetMapping(v)
+	if err != nil {
+		return err
+	}
+
+	pks, indexes, err := mapping.Fields.FindPKs()
+	if err != nil {
+		return err
+	}
+
+	d := dialectOf(db)
+	cols := make([]string, len(pks))
+	for i, f := range pks {
+		cols[i] = d.Quote(f.Column)
+	}
+
+	var (
+		key   []any
+		order = strings.Join(cols, ", ")
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		conds := make([]string, 0, 2)
+		if where != "" {
+			conds = append(conds, "("+where+")")
+		}
+
+		batchArgs := args
+		if key != nil {
+			conds = append(conds, keysetClause(d, cols, len(args)+1))
+			batchArgs = append(args[:len(args):len(args)], key...)
+		}
+
+		var sql string
+		if len(conds) > 0 {
+			sql = "WHERE " + strings.Join(conds, " AND ") + " "
+		}
+
+		sql += fmt.Sprintf("ORDER BY %s %s", order, d.LimitOffset(size, 0))
+
+		*v = (*v)[:0]
+		if err := batch(sql, batchArgs); err != nil {
+			return err
+		}
+
+		if len(*v) < size {
+			return nil
+		}
+
+		// the key is read from a copy as the next batch is scanned into the same backing array
+		last := (*v)[len(*v)-1]
+		key = make([]any, len(indexes))
+		for i, index := range indexes {
+			key[i] = getValueAtIndex(&last, index)
+		}
+	}
+}
+
+// keysetClause returns the condition matching rows after the key of the previous batch. Placeholders are numbered from start
+func keysetClause(d Dialect, cols []string, start int) string {
+	params := make([]string, len(cols))
+	for i := range cols {
+		params[i] = d.Placeholder(start + i)
+	}
+
+	if len(cols) == 1 {
+		return fmt.Sprintf("%s > %s", cols[0], params[0])
+	}
+
+	return fmt.Sprintf("(%s) > (%s)", strings.Join(cols, ", "), strings.Join(params, ", "))
+}
@@ -0,0 +1,52 @@
+package orm
+
+import "fmt"
+
+// Clause is a sql clause rendered for a dialect, such as a query.Builder.
+// Get, List, Count, Update and Remove accept a Clause in place of their sql string.
+type Clause interface {
+	Build(d Dialect) (string, []any)
+}
+
+// whereOnlyClause is implemented by clauses which report whether they hold WHERE conditions alone, such as a query.Builder
+type whereOnlyClause interface {
+	WhereOnly() bool
+}
+
+// clauseOf returns the sql string and arguments of the sql argument, which is either a string or a Clause built for the dialect of db.
+// The args of a string are bound with bindArgs. A Clause carries its own arguments, so none may be given along with it.
+func clauseOf(db any, sql any, args []any) (string, []any, error) {
+	switch s := sql.(type) {
+	case string:
+		return bindArgs(db, s, args)
+	case Clause:
+		if len(args) > 0 {
+			return "", nil, fmt.Errorf("%w: arguments can not be given along with a %T", ErrInvalidType, sql)
+		}
+
+		str, cargs := s.Build(dialectOf(db))
+		return str, cargs, nil
+	default:
+		return "", nil, fmt.Errorf("%w: %T is neither a sql string nor a Clause", ErrInvalidType, sql)
+	}
+}
+
+// whereOf returns the sql string and arguments of the sql argument like clauseOf, for statements which only take a WHERE clause such as UPDATE and DELETE.
+// ErrInvalidType is returned for a Clause with other parts, such as ORDER BY or LIMIT.
+func whereOf(db any, sql any, args []any) (string, []any, error) {
+	if w, ok := sql.(whereOnlyClause); ok && !w.WhereOnly() {
+		return "", nil, fmt.Errorf("%w: %T must only hold WHERE conditions to update or remove rows", ErrInvalidType, sql)
+	}
+
+	return clauseOf(db, sql, args)
+}
+
+// bindArgs binds the named parameters of sql and expands its InList arguments for the dialect of db
+func bindArgs(db any, sql string, args []any) (string, []any, error) {
+	sql, args, err := named(db, sql, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return expandIn(dialectOf(db), sql, args)
+}
@@ -0,0 +1,127 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrTxNotSupported is returned when a transaction is started from a handle which is already a transaction
+var ErrTxNotSupported = errors.New("transactions not supported")
+
+// conn binds a database handle to the configuration of an ORM.
+// It implements both the context-free and context aware interfaces so it can be passed to any orm func.
+type conn struct {
+	db           QuerierExecuterContext
+	beginner     BeginnerContext // nil when conn wraps a transaction
+	dialect      Dialect
+	clock        func() time.Time // nil when NowFunc is used
+	interceptors []Interceptor
+	strict       *bool // nil when StrictColumns is used
+}
+
+// withTx returns a copy of the conn which executes statements within tx
+func (c *conn) withTx(tx *sql.Tx) *conn {
+	cp := *c
+	cp.db = tx
+	cp.beginner = nil
+	return &cp
+}
+
+func (c *conn) Dialect() Dialect {
+	return c.dialect
+}
+
+func (c *conn) Now() time.Time {
+	if c.clock == nil {
+		return NowFunc()
+	}
+
+	return c.clock()
+}
+
+func (c *conn) StrictColumns() bool {
+	if c.strict == nil {
+		return StrictColumns
+	}
+
+	return *c.strict
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if c.beginner == nil {
+		return nil, ErrTxNotSupported
+	}
+
+	return c.beginner.BeginTx(ctx, opts)
+}
+
+func (c *conn) Begin() (*sql.Tx, error) {
+	return c.BeginTx(context.Background(), nil)
+}
+
+func (c *conn) ExecContext(ctx context.Context, sql string, args ...any) (sql.Result, error) {
+	if len(c.interceptors) == 0 {
+		return c.db.ExecContext(ctx, sql, args...)
+	}
+
+	res, err := c.intercept(ctx, OpExec, sql, args)
+	if err == nil && res.Result == nil {
+		err = ErrNoResponse
+	}
+
+	return res.Result, err
+}
+
+func (c *conn) Exec(sql string, args ...any) (sql.Result, error) {
+	return c.ExecContext(context.Background(), sql, args...)
+}
+
+func (c *conn) QueryContext(ctx context.Context, sql string, args ...any) (*sql.Rows, error) {
+	if len(c.interceptors) == 0 {
+		return c.db.QueryContext(ctx, sql, args...)
+	}
+
+	res, err := c.intercept(ctx, OpQuery, sql, args)
+	if err == nil && res.Rows == nil {
+		err = ErrNoResponse
+	}
+
+	return res.Rows, err
+}
+
+func (c *conn) Query(sql string, args ...any) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), sql, args...)
+}
+
+func (c *conn) QueryRowContext(ctx context.Context, sql string, args ...any) *sql.Row {
+	if len(c.interceptors) == 0 {
+		return c.db.QueryRowContext(ctx, sql, args...)
+	}
+
+	res, err := c.intercept(ctx, OpQueryRow, sql, args)
+	if res.Row != nil {
+		return res.Row
+	}
+
+	if err == nil {
+		err = ErrNoResponse
+	}
+
+	// a row can only carry an error when it is created by database/sql
+	return errRow(err)
+}
+
+func (c *conn) QueryRow(sql string, args ...any) *sql.Row {
+	return c.QueryRowContext(context.Background(), sql, args...)
+}
+
+// bindTx binds tx to the configuration of db when db is a conn, otherwise tx is returned as is
+func bindTx(db any, tx *sql.Tx) QuerierExecuterContext {
+	if c, ok := db.(*conn); ok {
+		return c.withTx(tx)
+	}
+
+	return tx
+}
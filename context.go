@@ -0,0 +1,79 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+)
+
+// contextAdapter satisfies the context aware interfaces on behalf of types which only implement the context-free ones.
+// The context is ignored, so calls behave exactly as they would without it.
+type contextAdapter struct {
+	Beginner
+	Querier
+	Executer
+}
+
+func (a *contextAdapter) BeginTx(_ context.Context, _ *sql.TxOptions) (*sql.Tx, error) {
+	if a.Beginner == nil {
+		return nil, ErrTxNotSupported
+	}
+
+	return a.Begin()
+}
+
+func (a *contextAdapter) ExecContext(_ context.Context, sql string, args ...any) (sql.Result, error) {
+	return a.Exec(sql, args...)
+}
+
+func (a *contextAdapter) QueryContext(_ context.Context, sql string, args ...any) (*sql.Rows, error) {
+	return a.Query(sql, args...)
+}
+
+func (a *contextAdapter) QueryRowContext(_ context.Context, sql string, args ...any) *sql.Row {
+	return a.QueryRow(sql, args...)
+}
+
+// querierContext returns q as a QuerierContext, adapting it if necessary
+func querierContext(q Querier) QuerierContext {
+	if qc, ok := q.(QuerierContext); ok {
+		return qc
+	}
+
+	return adapt(q)
+}
+
+// executerContext returns e as an ExecuterContext, adapting it if necessary
+func executerContext(e Executer) ExecuterContext {
+	if ec, ok := e.(ExecuterContext); ok {
+		return ec
+	}
+
+	return adapt(e)
+}
+
+// querierExecuterContext returns db as a QuerierExecuterContext, adapting it if necessary
+func querierExecuterContext(db QuerierExecuter) QuerierExecuterContext {
+	if qe, ok := db.(QuerierExecuterContext); ok {
+		return qe
+	}
+
+	return adapt(db)
+}
+
+// dbContext returns db as a DBContext, adapting it if necessary
+func dbContext(db DB) DBContext {
+	if dc, ok := db.(DBContext); ok {
+		return dc
+	}
+
+	return adapt(db)
+}
+
+// adapt wraps db in a contextAdapter exposing every context-free interface it implements
+func adapt(db any) *contextAdapter {
+	a := new(contextAdapter)
+	a.Beginner, _ = db.(Beginner)
+	a.Querier, _ = db.(Querier)
+	a.Executer, _ = db.(Executer)
+	return a
+}
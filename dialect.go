@@ -0,0 +1,48 @@
+package orm
+
+import (
+	"github.com/cristosal/orm/schema"
+)
+
+// Dialect is an alias for schema.Dialect.
+// It determines the placeholders, quoting and clauses used when statements are generated.
+type Dialect = schema.Dialect
+
+var (
+	// Postgres is the default dialect
+	Postgres = schema.Postgres
+
+	// SQLite dialect. RETURNING clauses require SQLite 3.35 or later
+	SQLite = schema.SQLite
+
+	// MySQL dialect. Generated ids are read through LastInsertId
+	MySQL = schema.MySQL
+
+	defaultDialect = Postgres
+)
+
+// dialecter is implemented by database handles which are bound to a dialect
+type dialecter interface {
+	Dialect() Dialect
+}
+
+// dialectOf returns the dialect bound to db, falling back to Postgres
+func dialectOf(db any) Dialect {
+	if d, ok := db.(dialecter); ok {
+		return d.Dialect()
+	}
+
+	return defaultDialect
+}
+
+// dialectForDriver infers the dialect from the name of a database/sql driver
+func dialectForDriver(driverName string) Dialect {
+	switch driverName {
+	case "sqlite", "sqlite3":
+		return SQLite
+	case "mysql":
+		return MySQL
+	default:
+		return defaultDialect
+	}
+}
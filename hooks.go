@@ -0,0 +1,80 @@
+package orm
+
+import "context"
+
+// Hooks are optional interfaces implemented by records to run logic around persistence.
+// They are invoked with the active db, so statements executed by a hook run within the caller's transaction, if any.
+// An error returned by a Before hook aborts the operation before any statement is executed,
+// an error returned by an After hook is returned to the caller after the statement has run.
+// The statement is only undone when the caller rolls back its transaction.
+type (
+	// BeforeAdder is invoked by Add and AddMany before the record is inserted
+	BeforeAdder interface {
+		BeforeAdd(ctx context.Context, db ExecuterContext) error
+	}
+
+	// AfterAdder is invoked by Add and AddMany after the record is inserted and its id is set
+	AfterAdder interface {
+		AfterAdd(ctx context.Context, db ExecuterContext) error
+	}
+
+	// BeforeUpdater is invoked by the Update family before the record is updated
+	BeforeUpdater interface {
+		BeforeUpdate(ctx context.Context, db ExecuterContext) error
+	}
+
+	// AfterUpdater is invoked by the Update family after the record is updated
+	AfterUpdater interface {
+		AfterUpdate(ctx context.Context, db ExecuterContext) error
+	}
+
+	// BeforeRemover is invoked by the Remove family before rows are removed
+	BeforeRemover interface {
+		BeforeRemove(ctx context.Context, db ExecuterContext) error
+	}
+
+	// AfterRemover is invoked by the Remove family after rows are removed
+	AfterRemover interface {
+		AfterRemove(ctx context.Context, db ExecuterContext) error
+	}
+
+	// AfterScanner is invoked by Scan after a row has been scanned into the record
+	AfterScanner interface {
+		AfterScan() error
+	}
+)
+
+// runHook invokes fn when v implements the hook H
+func runHook[H any](v any, fn func(H) error) error {
+	if h, ok := v.(H); ok {
+		return fn(h)
+	}
+
+	return nil
+}
+
+// withRemoveHooks runs remove between the BeforeRemove and AfterRemove hooks of v
+func withRemoveHooks(ctx context.Context, db ExecuterContext, v any, remove func() error) error {
+	if err := runHook(v, func(h BeforeRemover) error { return h.BeforeRemove(ctx, db) }); err != nil {
+		return err
+	}
+
+	if err := remove(); err != nil {
+		return err
+	}
+
+	return runHook(v, func(h AfterRemover) error { return h.AfterRemove(ctx, db) })
+}
+
+// withUpdateHooks runs update between the BeforeUpdate and AfterUpdate hooks of v
+func withUpdateHooks(ctx context.Context, db ExecuterContext, v any, update func() error) error {
+	if err := runHook(v, func(h BeforeUpdater) error { return h.BeforeUpdate(ctx, db) }); err != nil {
+		return err
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	return runHook(v, func(h AfterUpdater) error { return h.AfterUpdate(ctx, db) })
+}
@@ -0,0 +1,121 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// InList is an argument which expands into one placeholder per element of a slice. See In
+type InList struct {
+	values []any
+}
+
+// In marks slice to be expanded into a list of placeholders when given as an argument to Query, Get, List, Count, Update or Remove.
+// The placeholder it binds must be enclosed in parentheses, as in "WHERE id IN ($1)", and the placeholders which follow it are renumbered.
+// An empty slice expands into an empty subquery, so that IN matches no rows and NOT IN matches every row.
+// A value which is not a slice, or a []byte, is treated as a list of one.
+func In(slice any) InList {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || v.Type().Elem().Kind() == reflect.Uint8 {
+		return InList{values: []any{slice}}
+	}
+
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+
+	return InList{values: values}
+}
+
+// emptyList is a subquery without rows, valid in every dialect
+const emptyList = "SELECT NULL FROM (SELECT 1) AS orm_empty WHERE 1 = 0"
+
+// expandIn replaces the placeholders bound to an InList in sql with a placeholder per value,
+// renumbering the placeholders of the dialect which follow. The flattened arguments are returned along with sql.
+func expandIn(d Dialect, sql string, args []any) (string, []any, error) {
+	found := false
+	for _, arg := range args {
+		if _, ok := arg.(InList); ok {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return sql, args, nil
+	}
+
+	var (
+		flat   []any
+		starts = make([]int, len(args))
+	)
+
+	for i, arg := range args {
+		starts[i] = len(flat) + 1
+		if l, ok := arg.(InList); ok {
+			flat = append(flat, l.values...)
+		} else {
+			flat = append(flat, arg)
+		}
+	}
+
+	var (
+		sb       strings.Builder
+		numbered = schema.Numbered(d)
+		prefix   = strings.TrimSuffix(d.Placeholder(1), "1")
+		pos      int
+	)
+
+	for i := 0; i < len(sql); i++ {
+		if end := skipQuoted(sql, i); end > i {
+			sb.WriteString(sql[i:end])
+			i = end - 1
+			continue
+		}
+
+		if !strings.HasPrefix(sql[i:], prefix) {
+			sb.WriteByte(sql[i])
+			continue
+		}
+
+		end := i + len(prefix)
+		n := 0
+		if numbered {
+			for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+				n = n*10 + int(sql[end]-'0')
+				end++
+			}
+
+			// a lone prefix is not a placeholder
+			if end == i+len(prefix) {
+				sb.WriteByte(sql[i])
+				continue
+			}
+		} else {
+			pos++
+			n = pos
+		}
+
+		if n < 1 || n > len(args) {
+			return "", nil, fmt.Errorf("%w: no argument for placeholder %s", ErrInvalidType, sql[i:end])
+		}
+
+		l, ok := args[n-1].(InList)
+		switch {
+		case !ok:
+			sb.WriteString(d.Placeholder(starts[n-1]))
+		case len(l.values) == 0:
+			sb.WriteString(emptyList)
+		default:
+			sb.WriteString(schema.ValueListFor(d, len(l.values), starts[n-1]))
+		}
+
+		i = end - 1
+	}
+
+	return sb.String(), flat, nil
+}
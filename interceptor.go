@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// ErrNoResponse is returned when an interceptor short-circuits a statement without an error or a response for its operation
+var ErrNoResponse = errors.New("interceptor returned no response")
+
+// Op is the kind of call a statement is issued through
+type Op int
+
+const (
+	OpExec Op = iota
+	OpQuery
+	OpQueryRow
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpExec:
+		return "exec"
+	case OpQuery:
+		return "query"
+	case OpQueryRow:
+		return "query_row"
+	default:
+		return "unknown"
+	}
+}
+
+// Statement is a single call to the database as seen by interceptors.
+// Interceptors may modify the SQL and Args before passing the statement on.
+type Statement struct {
+	Op       Op
+	SQL      string
+	Args     []any
+	Mapping  *schema.StructMapping // Mapping the statement was generated for. Nil for raw sql
+	Dialect  Dialect               // Dialect of the ORM issuing the statement
+	Duration time.Duration         // Time spent executing the statement, set once it has run
+}
+
+// Response holds the result of a statement. Only the field matching the operation is set
+type Response struct {
+	Result sql.Result // Set for OpExec
+	Rows   *sql.Rows  // Set for OpQuery
+	Row    *sql.Row   // Set for OpQueryRow
+}
+
+// Handler executes a statement
+type Handler func(ctx context.Context, stmt *Statement) (Response, error)
+
+// Interceptor wraps the execution of every statement issued through an ORM.
+// It calls next to continue the chain or short-circuits by returning a response or error of its own.
+type Interceptor func(ctx context.Context, stmt *Statement, next Handler) (Response, error)
+
+// WithInterceptors adds interceptors to the ORM. The first interceptor is the outermost
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *ORM) { o.interceptors = append(o.interceptors, interceptors...) }
+}
+
+type mappingKey struct{}
+
+// withMapping returns a context carrying the mapping statements are generated for
+func withMapping(ctx context.Context, mapping *schema.StructMapping) context.Context {
+	return context.WithValue(ctx, mappingKey{}, mapping)
+}
+
+// mappingOf returns the mapping carried by ctx, if any
+func mappingOf(ctx context.Context) *schema.StructMapping {
+	mapping, _ := ctx.Value(mappingKey{}).(*schema.StructMapping)
+	return mapping
+}
+
+// intercept runs the statement through the interceptors of the conn
+func (c *conn) intercept(ctx context.Context, op Op, sql string, args []any) (Response, error) {
+	stmt := &Statement{Op: op, SQL: sql, Args: args, Mapping: mappingOf(ctx), Dialect: c.dialect}
+
+	handler := c.execute
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], handler
+		handler = func(ctx context.Context, stmt *Statement) (Response, error) {
+			return interceptor(ctx, stmt, next)
+		}
+	}
+
+	return handler(ctx, stmt)
+}
+
+// execute is the innermost handler which issues the statement to the database
+func (c *conn) execute(ctx context.Context, stmt *Statement) (res Response, err error) {
+	start := time.Now()
+	defer func() { stmt.Duration = time.Since(start) }()
+
+	switch stmt.Op {
+	case OpExec:
+		res.Result, err = c.db.ExecContext(ctx, stmt.SQL, stmt.Args...)
+	case OpQuery:
+		res.Rows, err = c.db.QueryContext(ctx, stmt.SQL, stmt.Args...)
+	default:
+		res.Row = c.db.QueryRowContext(ctx, stmt.SQL, stmt.Args...)
+		if res.Row != nil {
+			err = res.Row.Err()
+		}
+	}
+
+	return
+}
+
+// errRow returns a row which reports err, for QueryRow calls that are short-circuited with an error
+func errRow(err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err})
+	defer db.Close()
+	return db.QueryRow("")
+}
+
+// errConnector provides connections on which every statement fails with err
+type errConnector struct{ err error }
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) { return errConn(c), nil }
+func (c errConnector) Driver() driver.Driver                        { return nil }
+
+type errConn struct{ err error }
+
+func (c errConn) Prepare(string) (driver.Stmt, error) { return nil, c.err }
+func (c errConn) Close() error                        { return nil }
+func (c errConn) Begin() (driver.Tx, error)           { return nil, c.err }
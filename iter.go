@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// Cursor iterates over the rows of a query one at a time, without holding the whole result in memory.
+// It must be closed once done with, unless Next has returned false.
+type Cursor[T any] struct {
+	rows  *sql.Rows
+	value T
+	err   error
+}
+
+// Iter returns a cursor over the rows of T matched by the sql argument. See List for details on the sql argument
+func Iter[T any](db Querier, sql any, args ...any) (*Cursor[T], error) {
+	return IterContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// IterContext returns a cursor over the rows of T matched by the sql argument. See List for details on the sql argument
+func IterContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) (*Cursor[T], error) {
+	mapping, _, err := schema.GetMapping(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	sqlstr, args, err := clauseOf(db, sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = withMapping(ctx, mapping)
+
+	rows, err := db.QueryContext(ctx, selectSQL(ctx, dialectOf(db), mapping, sqlstr), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor[T]{rows: rows}, nil
+}
+
+// Next scans the next row, returning false when there are no rows left or an error occurred.
+// The cursor is closed once Next returns false
+func (c *Cursor[T]) Next() bool {
+	if c.err != nil || !c.rows.Next() {
+		c.rows.Close()
+		return false
+	}
+
+	var v T
+	if err := Scan(c.rows, &v); err != nil {
+		c.err = err
+		c.rows.Close()
+		return false
+	}
+
+	c.value = v
+	return true
+}
+
+// Value returns the row scanned by the last call to Next
+func (c *Cursor[T]) Value() T {
+	return c.value
+}
+
+// Err returns the error which stopped the iteration, if any
+func (c *Cursor[T]) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.rows.Err()
+}
+
+// Close closes the cursor, releasing its connection
+func (c *Cursor[T]) Close() error {
+	return c.rows.Close()
+}
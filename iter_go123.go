@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package orm
+
+import (
+	"context"
+	"iter"
+)
+
+// Seq returns the rows of T matched by the sql argument as an iterator, scanning them one at a time.
+// An error ends the iteration and is yielded along with a zero T. See List for details on the sql argument
+func Seq[T any](db Querier, sql any, args ...any) iter.Seq2[T, error] {
+	return SeqContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// SeqContext returns the rows of T matched by the sql argument as an iterator, scanning them one at a time.
+// An error ends the iteration and is yielded along with a zero T. See List for details on the sql argument
+func SeqContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		c, err := IterContext[T](ctx, db, sql, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		defer c.Close()
+
+		for c.Next() {
+			if !yield(c.Value(), nil) {
+				return
+			}
+		}
+
+		if err := c.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
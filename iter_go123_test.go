@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package orm_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/cristosal/orm"
+)
+
+func TestSeq(t *testing.T) {
+	type Metric struct {
+		ID    int64
+		Value int64
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"id", "value"},
+		Rows:    [][]driver.Value{{int64(1), int64(10)}, {int64(2), int64(20)}, {int64(3), int64(30)}},
+	}
+
+	var sum int64
+	for m, err := range orm.Seq[Metric](mockdb, "") {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sum += m.Value
+		if m.ID == 2 {
+			break
+		}
+	}
+
+	if sum != 30 {
+		t.Fatalf("expected iteration to stop after the second row, got sum %d", sum)
+	}
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// LogOptions configures the statements logged by LogInterceptor
+type LogOptions struct {
+	Level         slog.Leveler             // Level of successful statements. Defaults to Debug
+	ErrorLevel    slog.Leveler             // Level of failed statements. Defaults to Error
+	SlowThreshold time.Duration            // Statements taking longer are logged at Warn unless Level is higher. Zero disables it
+	Redact        func(i int, arg any) any // Returns the logged value of the argument at index i. Defaults to the type of the argument
+}
+
+// WithLogger logs every statement issued through the ORM to logger. See LogInterceptor
+func WithLogger(logger *slog.Logger, opts *LogOptions) Option {
+	return WithInterceptors(LogInterceptor(logger, opts))
+}
+
+// LogInterceptor returns an interceptor which logs every statement with its SQL, redacted arguments, duration, rows affected and error.
+// The default logger is used when logger is nil.
+func LogInterceptor(logger *slog.Logger, opts *LogOptions) Interceptor {
+	if opts == nil {
+		opts = &LogOptions{}
+	}
+
+	var (
+		okLevel  slog.Leveler = slog.LevelDebug
+		errLevel slog.Leveler = slog.LevelError
+		redact                = redactType
+	)
+
+	if opts.Level != nil {
+		okLevel = opts.Level
+	}
+
+	if opts.ErrorLevel != nil {
+		errLevel = opts.ErrorLevel
+	}
+
+	if opts.Redact != nil {
+		redact = opts.Redact
+	}
+
+	return func(ctx context.Context, stmt *Statement, next Handler) (Response, error) {
+		res, err := next(ctx, stmt)
+
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+
+		level := okLevel.Level()
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			level = errLevel.Level()
+		} else if opts.SlowThreshold > 0 && stmt.Duration > opts.SlowThreshold {
+			level = max(level, slog.LevelWarn)
+		}
+
+		if !l.Enabled(ctx, level) {
+			return res, err
+		}
+
+		args := make([]any, len(stmt.Args))
+		for i, arg := range stmt.Args {
+			args[i] = redact(i, arg)
+		}
+
+		attrs := []slog.Attr{
+			slog.String("op", stmt.Op.String()),
+			slog.String("sql", stmt.SQL),
+			slog.Any("args", args),
+			slog.Duration("duration", stmt.Duration),
+		}
+
+		if stmt.Mapping != nil {
+			attrs = append(attrs, slog.String("table", stmt.Mapping.Table))
+		}
+
+		if res.Result != nil {
+			if n, err := res.Result.RowsAffected(); err == nil {
+				attrs = append(attrs, slog.Int64("rows_affected", n))
+			}
+		}
+
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		l.LogAttrs(ctx, level, "orm statement", attrs...)
+		return res, err
+	}
+}
+
+// redactType hides the value of an argument, logging its type instead
+func redactType(_ int, arg any) any {
+	if arg == nil {
+		return nil
+	}
+
+	return fmt.Sprintf("%T", arg)
+}
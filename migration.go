@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -23,6 +24,8 @@ type (
 		Name string // name of migration must be unique
 	}
 
+	// MigrationFunc executes a migration within tx.
+	// Statements executed through tx bypass the interceptors of an ORM, only the bookkeeping of Migrate is intercepted.
 	MigrationFunc func(tx *sql.Tx) error
 )
 
@@ -41,7 +44,7 @@ func SetMigrationTable(table string) {
 // CreateMigrationTable creates the table where the migration history will be stored.
 // The name of the table can be configured using the SetMigrationTable method.
 func (o *ORM) CreateMigrationTable() error {
-	return CreateMigrationTable(o.DB)
+	return CreateMigrationTable(o.conn())
 }
 
 // CreateMigrationTable creates the table where the migration history will be stored.
@@ -49,16 +52,16 @@ func (o *ORM) CreateMigrationTable() error {
 func CreateMigrationTable(db DB) error {
 	sql := fmt.Sprintf(
 		`CREATE TABLE IF NOT EXISTS %s (
-			id SERIAL PRIMARY KEY, 
+			id %s PRIMARY KEY, 
 			name VARCHAR(255) NOT NULL UNIQUE
-		);`, migrationTable)
+		);`, migrationTable, dialectOf(db).TypeName("SERIAL"))
 
 	_, err := db.Exec(sql)
 	return err
 }
 
 // DropMigrationTable drops the migration history table
-func (o *ORM) DropMigrationTable() error { return DropMigrationTable(o.DB) }
+func (o *ORM) DropMigrationTable() error { return DropMigrationTable(o.conn()) }
 
 // DropMigrationTable drops the migration history table
 func DropMigrationTable(db DB) error {
@@ -66,10 +69,21 @@ func DropMigrationTable(db DB) error {
 }
 
 // Migrate database and execute it.
-func (o *ORM) Migrate(name string, fn MigrationFunc) error { return Migrate(o.DB, name, fn) }
+func (o *ORM) Migrate(name string, fn MigrationFunc) error { return Migrate(o.conn(), name, fn) }
+
+// MigrateContext migrates the database, executing the migration within a transaction bound to ctx.
+func (o *ORM) MigrateContext(ctx context.Context, name string, fn MigrationFunc) error {
+	return MigrateContext(ctx, o.conn(), name, fn)
+}
 
 // Migrate executes a migration
 func Migrate(db DB, name string, fn MigrationFunc) error {
+	return MigrateContext(context.Background(), dbContext(db), name, fn)
+}
+
+// MigrateContext executes a migration within a transaction bound to ctx.
+// The statements recording the migration go through the interceptors bound to db, but those executed by fn on the raw transaction do not
+func MigrateContext(ctx context.Context, db DBContext, name string, fn MigrationFunc) error {
 	m := &Migration{
 		Name: name,
 	}
@@ -80,33 +94,29 @@ func Migrate(db DB, name string, fn MigrationFunc) error {
 
 	// check if we have executed a migration with this name already
 	var found Migration
-	_ = Get(db, &found, "WHERE name = $1", m.Name)
+	_ = GetContext(ctx, db, &found, "WHERE name = "+dialectOf(db).Placeholder(1), m.Name)
 
 	if found.Name == m.Name {
 		return ErrMigrationAlreadyExists
 	}
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	defer tx.Rollback()
-
-	// execute migration
-	if err := fn(tx); err != nil {
-		return err
-	}
-
-	if err := Add(tx, m); err != nil {
-		return err
-	}
+	return runTx(func() error {
+		// execute migration
+		if err := fn(tx); err != nil {
+			return err
+		}
 
-	return tx.Commit()
+		return AddContext(ctx, bindTx(db, tx), m)
+	}, tx.Commit, tx.Rollback)
 }
 
 // ListMigrations returns all migrations that have been executed
-func (o *ORM) ListMigrations() ([]Migration, error) { return ListMigrations(o.DB) }
+func (o *ORM) ListMigrations() ([]Migration, error) { return ListMigrations(o.conn()) }
 
 // ListMigrations returns all migrations
 func ListMigrations(db DB) ([]Migration, error) {
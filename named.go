@@ -0,0 +1,213 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// ErrMissingParam is returned when a named parameter has no value in the map or struct it is bound from
+var ErrMissingParam = errors.New("missing named parameter")
+
+// named replaces the :name and @name parameters of sql with placeholders of the dialect of db when args hold a single map or struct.
+// Parameters are bound from the keys of a map[string]any, or the column names of a struct.
+// Other args, and sql without named parameters, are returned as is.
+// ErrInvalidType is returned when sql has named parameters and a map or struct is given along with other args.
+func named(db any, sql string, args []any) (string, []any, error) {
+	if len(args) > 1 {
+		return sql, args, mixedNamed(dialectOf(db), sql, args)
+	}
+
+	if len(args) == 0 {
+		return sql, args, nil
+	}
+
+	lookup, err := namedLookup(args[0])
+	if err != nil || lookup == nil {
+		return sql, args, err
+	}
+
+	bound, bargs, err := bindNamed(dialectOf(db), sql, lookup)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// a map or struct may itself be the value of a positional parameter, such as a json column
+	if len(bargs) == 0 {
+		return sql, args, nil
+	}
+
+	return bound, bargs, nil
+}
+
+// namedLookup returns a func resolving parameter names to values of arg, or nil when arg is an ordinary argument
+func namedLookup(arg any) (func(name string) (any, bool), error) {
+	switch arg.(type) {
+	case driver.Valuer, time.Time, *time.Time, InList:
+		return nil, nil
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(arg))
+	switch val.Kind() {
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, nil
+		}
+
+		return func(name string) (any, bool) {
+			v := val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+
+			return v.Interface(), true
+		}, nil
+	case reflect.Struct:
+		var (
+			mapping *schema.StructMapping
+			err     error
+		)
+
+		// anonymous structs share the empty table name, so their mappings can not be cached
+		if val.Type().Name() == "" {
+			mapping, _, err = schema.ParseMapping(arg)
+		} else {
+			mapping, _, err = schema.GetMapping(arg)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// values are read from an addressable copy so that pointer receivers of driver.Valuer are honoured
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+
+		return func(name string) (any, bool) {
+			_, index, err := mapping.Fields.FindByColumn(name)
+			if err != nil {
+				return nil, false
+			}
+
+			return getValueAtIndex(ptr.Interface(), index), true
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// mixedNamed returns an error when sql has named parameters and a map or struct is among several args
+func mixedNamed(d Dialect, sql string, args []any) error {
+	mixed := slices.ContainsFunc(args, func(arg any) bool {
+		lookup, _ := namedLookup(arg)
+		return lookup != nil
+	})
+
+	if !mixed {
+		return nil
+	}
+
+	// every name resolves so that only the presence of named parameters is checked
+	_, bound, _ := bindNamed(d, sql, func(string) (any, bool) { return nil, true })
+	if len(bound) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: named parameters are bound from a single map or struct, got %d arguments", ErrInvalidType, len(args))
+}
+
+// bindNamed rewrites the named parameters of sql to placeholders of the dialect, returning the values they bind.
+// String literals, quoted identifiers, comments and :: casts are left untouched.
+// A parameter used more than once is bound once for numbered placeholders, and at every use otherwise.
+func bindNamed(d Dialect, sql string, lookup func(string) (any, bool)) (string, []any, error) {
+	var (
+		sb       strings.Builder
+		args     []any
+		numbers  = make(map[string]int)
+		numbered = schema.Numbered(d)
+	)
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		if end := skipQuoted(sql, i); end > i {
+			sb.WriteString(sql[i:end])
+			i = end - 1
+			continue
+		}
+
+		switch {
+		case (c == ':' || c == '@') && i+1 < len(sql) && sql[i+1] == c:
+			// :: casts and @@ system variables
+			sb.WriteString(sql[i : i+2])
+			i++
+		case (c == ':' || c == '@') && i+1 < len(sql) && isNameStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isNamePart(sql[end]) {
+				end++
+			}
+
+			name := sql[i+1 : end]
+			i = end - 1
+
+			if n, ok := numbers[name]; ok && numbered {
+				sb.WriteString(d.Placeholder(n))
+				continue
+			}
+
+			v, ok := lookup(name)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %c%s", ErrMissingParam, c, name)
+			}
+
+			args = append(args, v)
+			numbers[name] = len(args)
+			sb.WriteString(d.Placeholder(len(args)))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), args, nil
+}
+
+// skipQuoted returns the index following the string literal, quoted identifier or comment starting at index i of sql,
+// or i when none starts there
+func skipQuoted(sql string, i int) int {
+	switch {
+	case sql[i] == '\'' || sql[i] == '"' || sql[i] == '`':
+		return skipPast(sql, i+1, sql[i:i+1])
+	case strings.HasPrefix(sql[i:], "--"):
+		return skipPast(sql, i+2, "\n")
+	case strings.HasPrefix(sql[i:], "/*"):
+		return skipPast(sql, i+2, "*/")
+	case strings.HasPrefix(sql[i:], "$$"):
+		return skipPast(sql, i+2, "$$")
+	default:
+		return i
+	}
+}
+
+// skipPast returns the index following the first occurrence of delim in sql from start, or the length of sql when there is none
+func skipPast(sql string, start int, delim string) int {
+	end := strings.Index(sql[start:], delim)
+	if end < 0 {
+		return len(sql)
+	}
+
+	return start + end + len(delim)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
@@ -1,17 +1,31 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/cristosal/orm/schema"
 )
 
 type (
-	ORM struct{ DB }
+	// ORM binds a DB to its configuration, such as the dialect used to generate statements
+	ORM struct {
+		DB
+		dialect      Dialect
+		clock        func() time.Time
+		interceptors []Interceptor
+		strict       *bool // nil when StrictColumns is used
+		txDepth      int   // number of transactions and savepoints the DB is nested in
+	}
+
+	// Option configures an ORM
+	Option func(*ORM)
 
 	// DB interface allows for interoperability between sql.Tx and sql.DB types
 	DB interface {
@@ -19,24 +33,57 @@ type (
 		QuerierExecuter
 	}
 
+	// DBContext is the context aware counterpart of DB
+	DBContext interface {
+		BeginnerContext
+		QuerierExecuterContext
+	}
+
+	// Conn implements both DB and DBContext. See ORM.Conn
+	Conn interface {
+		DB
+		DBContext
+	}
+
 	Beginner interface {
 		Begin() (*sql.Tx, error)
 	}
 
+	// BeginnerContext starts transactions which are bound to a context
+	BeginnerContext interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+
 	Executer interface {
 		Exec(sql string, args ...any) (sql.Result, error)
 	}
 
+	// ExecuterContext executes statements which are cancelled when the context is done
+	ExecuterContext interface {
+		ExecContext(ctx context.Context, sql string, args ...any) (sql.Result, error)
+	}
+
 	Querier interface {
 		Query(sql string, args ...any) (*sql.Rows, error)
 		QueryRow(sql string, args ...any) *sql.Row
 	}
 
+	// QuerierContext executes queries which are cancelled when the context is done
+	QuerierContext interface {
+		QueryContext(ctx context.Context, sql string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, sql string, args ...any) *sql.Row
+	}
+
 	QuerierExecuter interface {
 		Querier
 		Executer
 	}
 
+	QuerierExecuterContext interface {
+		QuerierContext
+		ExecuterContext
+	}
+
 	Rows interface {
 		Next() bool
 		Close() error
@@ -56,64 +103,152 @@ var (
 	// The error occurs when the interface passed in as the v argument of an orm func is invalid.
 	// Most orm funcs accept either a pointer to a struct, or pointer to a slice of structs.
 	ErrInvalidType = schema.ErrInvalidType
+
+	// ErrTooManyParams is returned when a single record needs more bind parameters than the dialect allows in a statement
+	ErrTooManyParams = errors.New("too many parameters")
 )
 
-func Open(driverName, dataSourceName string) (*ORM, error) {
+// Open opens a database and returns an ORM for it.
+// The dialect is inferred from the driver name unless set with the WithDialect option.
+func Open(driverName, dataSourceName string, opts ...Option) (*ORM, error) {
 	sqlDB, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
 
-	return New(sqlDB), nil
+	opts = append([]Option{WithDialect(dialectForDriver(driverName))}, opts...)
+	return New(sqlDB, opts...), nil
+}
+
+// New returns an ORM for db configured with opts
+func New(db DB, opts ...Option) *ORM {
+	o := &ORM{DB: db}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithDialect sets the dialect used to generate statements
+func WithDialect(d Dialect) Option {
+	return func(o *ORM) { o.dialect = d }
+}
+
+// WithClock sets the clock used to fill autocreate and autoupdate fields. Defaults to NowFunc
+func WithClock(now func() time.Time) Option {
+	return func(o *ORM) { o.clock = now }
+}
+
+// Dialect returns the dialect used by the ORM
+func (o *ORM) Dialect() Dialect {
+	if o.dialect != nil {
+		return o.dialect
+	}
+
+	return defaultDialect
 }
 
-func New(db DB) *ORM { return &ORM{db} }
+// Conn returns the underlying DB bound to the configuration of the ORM.
+// Pass it to funcs without an ORM method, such as Paginate, so that they use the dialect, clock and interceptors of the ORM.
+func (o *ORM) Conn() Conn {
+	return o.conn()
+}
+
+// conn binds the underlying DB to the configuration of the ORM
+func (o *ORM) conn() *conn {
+	db := dbContext(o.DB)
+	return &conn{db: db, beginner: db, dialect: o.Dialect(), clock: o.clock, interceptors: o.interceptors, strict: o.strict}
+}
 
 // Exec executes the sql string returning any error encountered
-func (o *ORM) Exec(sql string, args ...any) error { return Exec(o.DB, sql, args...) }
+func (o *ORM) Exec(sql string, args ...any) error { return Exec(o.conn(), sql, args...) }
+
+// ExecContext executes the sql string returning any error encountered
+func (o *ORM) ExecContext(ctx context.Context, sql string, args ...any) error {
+	return ExecContext(ctx, o.conn(), sql, args...)
+}
 
 // Exec executes the sql string returning any error encountered
 func Exec(db Executer, sql string, args ...any) error {
-	_, err := db.Exec(sql, args...)
+	return ExecContext(context.Background(), executerContext(db), sql, args...)
+}
+
+// ExecContext executes the sql string returning any error encountered
+func ExecContext(ctx context.Context, db ExecuterContext, sql string, args ...any) error {
+	_, err := db.ExecContext(ctx, sql, args...)
 	return err
 }
 
 // Query executes an sql statement and scans the result set into v
 func (o *ORM) Query(v any, sql string, args ...any) error {
-	return Query(o.DB, v, sql, args...)
+	return Query(o.conn(), v, sql, args...)
+}
+
+// QueryContext executes an sql statement and scans the result set into v
+func (o *ORM) QueryContext(ctx context.Context, v any, sql string, args ...any) error {
+	return QueryContext(ctx, o.conn(), v, sql, args...)
 }
 
 // Query executes an sql statement and scans the result set into v
 func Query(db Querier, v any, sql string, args ...any) error {
+	return QueryContext(context.Background(), querierContext(db), v, sql, args...)
+}
+
+// QueryContext executes an sql statement and scans the result set into v.
+// Columns are matched to fields by name, so their order does not matter. See StrictColumns for columns without a field
+func QueryContext(ctx context.Context, db QuerierContext, v any, sql string, args ...any) error {
 	mapping, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	slice := reflect.ValueOf(v).Elem()
+	ctx = withMapping(ctx, mapping)
+
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Pointer || slice.Elem().Kind() != reflect.Slice {
+		return ErrInvalidType
+	}
+
+	slice = slice.Elem()
+
+	sql, args, err = bindArgs(db, sql, args)
+	if err != nil {
+		return err
+	}
 
-	rows, err := db.Query(sql, args...)
+	rows, err := db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
 
 	defer rows.Close()
 
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields, err := columnFields(mapping, cols, strictOf(db))
+	if err != nil {
+		return err
+	}
+
 	for rows.Next() {
 		row := reflect.New(mapping.Type)
-		if err := Scan(rows, row.Interface()); err != nil {
+		if err := scanColumns(rows, row.Interface(), fields); err != nil {
 			return err
 		}
 
 		slice.Set(reflect.Append(slice, row.Elem()))
 	}
 
-	return nil
+	return rows.Err()
 }
 
 // List is a select over columns defined in v
 func (o *ORM) ListWhere(v any, sql string, args ...any) error {
-	return ListWhere(o.DB, v, sql, args...)
+	return ListWhere(o.conn(), v, sql, args...)
 }
 
 // List is a select over columns defined in v
@@ -122,24 +257,41 @@ func ListWhere(db Querier, v any, sql string, args ...any) error {
 }
 
 // List is a select over columns defined in v
-func (o *ORM) List(v any, sql string, args ...any) error {
-	return List(o.DB, v, sql, args...)
+func (o *ORM) List(v any, sql any, args ...any) error {
+	return List(o.conn(), v, sql, args...)
+}
+
+// ListContext is a select over columns defined in v
+func (o *ORM) ListContext(ctx context.Context, v any, sql any, args ...any) error {
+	return ListContext(ctx, o.conn(), v, sql, args...)
 }
 
 // List is a select over columns defined in v
-func List(db Querier, v any, sql string, args ...any) error {
+func List(db Querier, v any, sql any, args ...any) error {
+	return ListContext(context.Background(), querierContext(db), v, sql, args...)
+}
+
+// ListContext is a select over columns defined in v.
+// The sql argument is a string or a Clause and is placed immediately after the SELECT statement.
+func ListContext(ctx context.Context, db QuerierContext, v any, sql any, args ...any) error {
 	mapping, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	var (
-		val  = reflect.ValueOf(v).Elem()
-		cols = mapping.Fields.Columns().List()
-		sql2 = fmt.Sprintf("SELECT %s FROM %s %s", cols, mapping.Table, sql)
-	)
+	sqlstr, args, err := clauseOf(db, sql, args)
+	if err != nil {
+		return err
+	}
 
-	rows, err := db.Query(strings.Trim(sql2, " "), args...)
+	ctx = withMapping(ctx, mapping)
+
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Slice {
+		return ErrInvalidType
+	}
+
+	rows, err := db.QueryContext(ctx, selectSQL(ctx, dialectOf(db), mapping, sqlstr), args...)
 	if err != nil {
 		return err
 	}
@@ -152,40 +304,99 @@ func List(db Querier, v any, sql string, args ...any) error {
 			return err
 		}
 
-		val.Set(reflect.Append(val, row.Elem()))
+		val.Elem().Set(reflect.Append(val.Elem(), row.Elem()))
 	}
 
-	return nil
+	return rows.Err()
+}
+
+// selectSQL returns a select over the columns of the mapping followed by the sql argument
+func selectSQL(ctx context.Context, d Dialect, mapping *schema.StructMapping, sql string) string {
+	cols := mapping.Fields.Columns().Quoted(d).List()
+	return strings.Trim(fmt.Sprintf("SELECT %s FROM %s %s", cols, fromClause(ctx, d, mapping), sql), " ")
 }
 
 func (o *ORM) QueryRow(v any, sql string, args ...any) error {
-	return QueryRow(o.DB, v, sql, args...)
+	return QueryRow(o.conn(), v, sql, args...)
+}
+
+func (o *ORM) QueryRowContext(ctx context.Context, v any, sql string, args ...any) error {
+	return QueryRowContext(ctx, o.conn(), v, sql, args...)
 }
 
 // QueryRow executes a given sql query and scans the result into v
 func QueryRow(db Querier, v any, sql string, args ...any) error {
-	_, _, err := schema.GetMapping(v)
+	return QueryRowContext(context.Background(), querierContext(db), v, sql, args...)
+}
+
+// QueryRowContext executes a given sql query and scans the first row of the result into v.
+// Columns are matched to fields by name, leaving fields without a column untouched. See StrictColumns for columns without a field
+func QueryRowContext(ctx context.Context, db QuerierContext, v any, sql string, args ...any) error {
+	mapping, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	row := db.QueryRow(sql, args...)
-	return Scan(row, v)
+	ctx = withMapping(ctx, mapping)
+
+	sql, args, err = bindArgs(db, sql, args)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return ErrNotFound
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields, err := columnFields(mapping, cols, strictOf(db))
+	if err != nil {
+		return err
+	}
+
+	if err := scanColumns(rows, v, fields); err != nil {
+		return err
+	}
+
+	return rows.Close()
 }
 
 func (o *ORM) GetWhere(v any, sql string, args ...any) error {
-	return Get(o.DB, v, "WHERE "+sql, args...)
+	return Get(o.conn(), v, "WHERE "+sql, args...)
 }
 
 func GetString(db Querier, sql string, args ...any) (string, error) {
-	row := db.QueryRow(sql, args...)
+	return GetStringContext(context.Background(), querierContext(db), sql, args...)
+}
+
+func GetStringContext(ctx context.Context, db QuerierContext, sql string, args ...any) (string, error) {
+	row := db.QueryRowContext(ctx, sql, args...)
 	var str string
 	err := row.Scan(&str)
 	return str, err
 }
 
-func (o *ORM) Get(v any, sql string, args ...any) error {
-	return Get(o.DB, v, sql, args...)
+func (o *ORM) Get(v any, sql any, args ...any) error {
+	return Get(o.conn(), v, sql, args...)
+}
+
+func (o *ORM) GetContext(ctx context.Context, v any, sql any, args ...any) error {
+	return GetContext(ctx, o.conn(), v, sql, args...)
 }
 
 func GetWhere(db Querier, v any, s string, args ...any) error {
@@ -193,22 +404,38 @@ func GetWhere(db Querier, v any, s string, args ...any) error {
 }
 
 // Get returns the first row encountered.
-// The sql string is placed immediately after the SELECT statement.
-func Get(db Querier, v any, s string, args ...any) error {
+// The sql argument is a string or a Clause and is placed immediately after the SELECT statement.
+func Get(db Querier, v any, s any, args ...any) error {
+	return GetContext(context.Background(), querierContext(db), v, s, args...)
+}
+
+// GetContext returns the first row encountered.
+// The sql argument is a string or a Clause and is placed immediately after the SELECT statement.
+func GetContext(ctx context.Context, db QuerierContext, v any, sql any, args ...any) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	cols := sch.Fields.Columns().List()
+	s, args, err := clauseOf(db, sql, args)
+	if err != nil {
+		return err
+	}
 
-	q := fmt.Sprintf("SELECT %s FROM %s", cols, sch.Table)
+	ctx = withMapping(ctx, sch)
+
+	var (
+		d    = dialectOf(db)
+		cols = sch.Fields.Columns().Quoted(d).List()
+	)
+
+	q := fmt.Sprintf("SELECT %s FROM %s", cols, fromClause(ctx, d, sch))
 	// append sql argument if not empty
 	if s != "" {
 		q = fmt.Sprintf("%s %s", q, s)
 	}
 
-	row := db.QueryRow(q, args...)
+	row := db.QueryRowContext(ctx, q, args...)
 	if row == nil {
 		return ErrNotFound
 	}
@@ -221,28 +448,35 @@ func Get(db Querier, v any, s string, args ...any) error {
 }
 
 func (o *ORM) GetByID(v any) error {
-	return GetByID(o.DB, v)
+	return GetByID(o.conn(), v)
+}
+
+func (o *ORM) GetByIDContext(ctx context.Context, v any) error {
+	return GetByIDContext(ctx, o.conn(), v)
 }
 
 func GetByID(db Querier, v any) error {
+	return GetByIDContext(context.Background(), querierContext(db), v)
+}
+
+func GetByIDContext(ctx context.Context, db QuerierContext, v any) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	f, index, err := sch.Fields.FindPK()
+	ctx = withMapping(ctx, sch)
+
+	cond, vals, err := pkCondition(dialectOf(db), sch, v, 1)
 	if err != nil {
 		return err
 	}
 
-	val := getValueAtIndex(v, index)
-	col := f.Column
-
-	return Get(db, v, fmt.Sprintf("WHERE %s = $1", col), val)
+	return GetContext(ctx, db, v, "WHERE "+cond, vals...)
 }
 
 func (o *ORM) ListAll(v any) error {
-	return ListAll(o.DB, v)
+	return ListAll(o.conn(), v)
 }
 
 // ListAll is the same as List with an empty sql string.
@@ -252,31 +486,61 @@ func ListAll(db Querier, v any) error {
 }
 
 func (o *ORM) Add(v any) error {
-	return Add(o.DB, v)
+	return Add(o.conn(), v)
+}
+
+func (o *ORM) AddContext(ctx context.Context, v any) error {
+	return AddContext(ctx, o.conn(), v)
 }
 
 // Add inserts v into designated table. ID is set on v if available
 func Add(db QuerierExecuter, v any) error {
+	return AddContext(context.Background(), querierExecuterContext(db), v)
+}
+
+// AddContext inserts v into designated table. ID is set on v if available
+func AddContext(ctx context.Context, db QuerierExecuterContext, v any) error {
+	if err := runHook(v, func(h BeforeAdder) error { return h.BeforeAdd(ctx, db) }); err != nil {
+		return err
+	}
+
+	if err := add(ctx, db, v); err != nil {
+		return err
+	}
+
+	return runHook(v, func(h AfterAdder) error { return h.AfterAdd(ctx, db) })
+}
+
+// add inserts v without running its hooks
+func add(ctx context.Context, db QuerierExecuterContext, v any) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
+	ctx = withMapping(ctx, sch)
+
+	if err := stampAdded(sch, v, nowOf(db)); err != nil {
+		return err
+	}
+
 	// get the writeable columns
-	cols := sch.Fields.Writeable().Columns()
+	var (
+		cols = sch.Fields.Writeable().Columns()
+		d    = dialectOf(db)
+	)
 
 	// if it was an array we would neet to iterate over it
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", sch.Table, cols.List(), cols.ValueList(1))
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.Quote(sch.Table), cols.Quoted(d).List(), cols.ValueListFor(d, 1))
 
 	vals, err := schema.Values(v)
 	if err != nil {
 		return err
 	}
 
-	// the below only happens in postgres (returning clause)
-	id, index, err := sch.Fields.FindPK()
+	id, index, err := findGeneratedPK(sch)
 	if errors.Is(err, schema.ErrFieldNotFound) {
-		return Exec(db, sql, vals...)
+		return ExecContext(ctx, db, sql, vals...)
 	}
 
 	// other possible error
@@ -284,63 +548,201 @@ func Add(db QuerierExecuter, v any) error {
 		return err
 	}
 
-	sql = fmt.Sprintf("%s returning %s", sql, id.Column)
-	row := db.QueryRow(sql, vals...)
+	// dialects without a returning clause report the id through the result
+	if !d.Returning() {
+		res, err := db.ExecContext(ctx, sql, vals...)
+		if err != nil {
+			return err
+		}
+
+		if !d.LastInsertID() {
+			return nil
+		}
+
+		return setLastInsertID(res, reflect.ValueOf(v).Elem().FieldByIndex(index))
+	}
+
+	sql = fmt.Sprintf("%s returning %s", sql, d.Quote(id.Column))
+	row := db.QueryRowContext(ctx, sql, vals...)
+	if row == nil {
+		return ErrNotFound
+	}
+
 	addr := getAddrAtIndex(v, index)
 	return row.Scan(addr)
 }
 
 func (o *ORM) AddMany(v any) error {
-	return AddMany(o.DB, v)
+	return AddMany(o.conn(), v)
 }
 
-func AddMany(db Executer, v any) error {
-	slice := reflect.ValueOf(v)
+func (o *ORM) AddManyContext(ctx context.Context, v any) error {
+	return AddManyContext(ctx, o.conn(), v)
+}
+
+// AddMany inserts all records of the slice v. Generated ids are scanned back into each record when the dialect supports RETURNING.
+// Records are inserted in batches which respect the parameter limit of the dialect.
+// When more than one batch is required and db is able to begin a transaction, all batches are inserted within it.
+func AddMany(db QuerierExecuter, v any) error {
+	return AddManyContext(context.Background(), querierExecuterContext(db), v)
+}
+
+// AddManyContext inserts all records of the slice v. See AddMany for details
+func AddManyContext(ctx context.Context, db QuerierExecuterContext, v any) error {
+	slice := reflect.Indirect(reflect.ValueOf(v))
 
 	if slice.Kind() != reflect.Slice {
 		return ErrInvalidType
 	}
 
-	// ok now what so now we need to find out if we can loop it and get address
+	if slice.Len() == 0 {
+		return nil
+	}
+
 	mapping, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
 	var (
+		d       = dialectOf(db)
+		columns = mapping.Fields.Writeable().Columns()
+		size    = min(slice.Len(), d.MaxParams()/max(columns.Len(), 1))
+	)
+
+	if size < 1 {
+		return fmt.Errorf("%w: %s has %d columns but %s allows %d", ErrTooManyParams, mapping.Table, columns.Len(), d.Name(), d.MaxParams())
+	}
+
+	if size == slice.Len() {
+		return addBatches(ctx, db, mapping, slice, size)
+	}
+
+	beginner, ok := db.(BeginnerContext)
+	if !ok {
+		return addBatches(ctx, db, mapping, slice, size)
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if errors.Is(err, ErrTxNotSupported) {
+		return addBatches(ctx, db, mapping, slice, size)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := addBatches(ctx, bindTx(db, tx), mapping, slice, size); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addBatches inserts the records of slice in batches of the given size, running the hooks of each record
+func addBatches(ctx context.Context, db QuerierExecuterContext, mapping *schema.StructMapping, slice reflect.Value, size int) error {
+	now := nowOf(db)
+	for i := 0; i < slice.Len(); i++ {
+		record := slice.Index(i).Addr().Interface()
+		if err := runHook(record, func(h BeforeAdder) error { return h.BeforeAdd(ctx, db) }); err != nil {
+			return err
+		}
+
+		if err := stampAdded(mapping, record, now); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < slice.Len(); start += size {
+		end := min(start+size, slice.Len())
+		if err := addBatch(ctx, db, mapping, slice.Slice(start, end)); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		record := slice.Index(i).Addr().Interface()
+		if err := runHook(record, func(h AfterAdder) error { return h.AfterAdd(ctx, db) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addBatch inserts all records of batch with a single statement, scanning generated ids back into each record
+func addBatch(ctx context.Context, db QuerierExecuterContext, mapping *schema.StructMapping, batch reflect.Value) error {
+	ctx = withMapping(ctx, mapping)
+
+	var (
+		d          = dialectOf(db)
 		columns    = mapping.Fields.Writeable().Columns()
 		counter    = 1
 		valueParts []string
 		args       []any
 	)
 
-	for i := 0; i < slice.Len(); i++ {
-		record := slice.Index(i)
-		vals, err := schema.Values(record.Interface())
+	for i := 0; i < batch.Len(); i++ {
+		record := batch.Index(i)
+		vals, err := schema.Values(record.Addr().Interface())
 		if err != nil {
 			return err
 		}
 
 		args = append(args, vals...)
-		valueParts = append(valueParts, "("+columns.ValueList(counter)+")")
+		valueParts = append(valueParts, "("+columns.ValueListFor(d, counter)+")")
 		counter += columns.Len()
 	}
 
-	// lets not do a returning
 	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-		mapping.Table, columns.List(), strings.Join(valueParts, ", "))
+		d.Quote(mapping.Table), columns.Quoted(d).List(), strings.Join(valueParts, ", "))
+
+	id, index, err := findGeneratedPK(mapping)
+	if errors.Is(err, schema.ErrFieldNotFound) || !d.Returning() {
+		return ExecContext(ctx, db, sql, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// rows are returned in the order of the values list
+	sql = fmt.Sprintf("%s returning %s", sql, d.Quote(id.Column))
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for i := 0; i < batch.Len() && rows.Next(); i++ {
+		addr := batch.Index(i).FieldByIndex(index).Addr().Interface()
+		if err := rows.Scan(addr); err != nil {
+			return err
+		}
+	}
 
-	return Exec(db, sql, args...)
+	return rows.Err()
 }
 
 func (o *ORM) DropTable(v any) error {
-	return DropTable(o.DB, v)
+	return DropTable(o.conn(), v)
+}
+
+func (o *ORM) DropTableContext(ctx context.Context, v any) error {
+	return DropTableContext(ctx, o.conn(), v)
 }
 
 func DropTable(db Executer, v any) error {
+	return DropTableContext(context.Background(), executerContext(db), v)
+}
+
+func DropTableContext(ctx context.Context, db ExecuterContext, v any) error {
 	if str, ok := v.(string); ok {
 		s := fmt.Sprintf("DROP TABLE %s", str)
-		return Exec(db, s)
+		return ExecContext(ctx, db, s)
 	}
 
 	sch, _, err := schema.GetMapping(v)
@@ -348,128 +750,255 @@ func DropTable(db Executer, v any) error {
 		return err
 	}
 
-	s := fmt.Sprintf("DROP TABLE %s", sch.Table)
-	return Exec(db, s)
+	ctx = withMapping(ctx, sch)
+
+	s := fmt.Sprintf("DROP TABLE %s", dialectOf(db).Quote(sch.Table))
+	return ExecContext(ctx, db, s)
+}
+
+func (o *ORM) Remove(v any, sql any, args ...any) error {
+	return Remove(o.conn(), v, sql, args...)
 }
 
-func (o *ORM) Remove(v any, sql string, args ...any) error {
-	return Remove(o.DB, v, sql, args...)
+func (o *ORM) RemoveContext(ctx context.Context, v any, sql any, args ...any) error {
+	return RemoveContext(ctx, o.conn(), v, sql, args...)
 }
 
 func (o *ORM) RemoveWhere(v any, sql string, args ...any) error {
-	return RemoveWhere(o.DB, v, sql, args...)
+	return RemoveWhere(o.conn(), v, sql, args...)
 }
 
 func RemoveWhere(db Executer, v any, s string, args ...any) error {
+	return Remove(db, v, "WHERE "+s, args...)
+}
+
+func Remove(db Executer, v any, s any, args ...any) error {
+	return RemoveContext(context.Background(), executerContext(db), v, s, args...)
+}
+
+// RemoveContext deletes the rows matched by the sql argument, which is a string or a Clause of WHERE conditions.
+// When v has a softdelete field the rows are marked as deleted instead, see HardRemoveContext.
+func RemoveContext(ctx context.Context, db ExecuterContext, v any, sql any, args ...any) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
-	sqlstr := fmt.Sprintf("DELETE FROM %s WHERE %s", sch.Table, s)
-	return Exec(db, sqlstr, args...)
-}
 
-func Remove(db Executer, v any, s string, args ...any) error {
-	sch, _, err := schema.GetMapping(v)
+	s, args, err := whereOf(db, sql, args)
 	if err != nil {
 		return err
 	}
-	sqlstr := fmt.Sprintf("DELETE FROM %s %s", sch.Table, s)
-	return Exec(db, sqlstr, args...)
+
+	return withRemoveHooks(ctx, db, v, func() error {
+		ctx := withMapping(ctx, sch)
+		field, _, err := findSoftDelete(sch)
+		if err != nil {
+			return hardRemove(ctx, db, sch, s, args...)
+		}
+
+		return ExecContext(ctx, db, softRemoveSQL(dialectOf(db), sch, field, s), args...)
+	})
 }
 
 func (o *ORM) RemoveByID(v any) error {
-	return RemoveByID(o.DB, v)
+	return RemoveByID(o.conn(), v)
+}
+
+func (o *ORM) RemoveByIDContext(ctx context.Context, v any) error {
+	return RemoveByIDContext(ctx, o.conn(), v)
 }
 
 func RemoveByID(db Executer, v any) error {
+	return RemoveByIDContext(context.Background(), executerContext(db), v)
+}
+
+// RemoveByIDContext deletes the row of v by its primary key.
+// When v has a softdelete field the row is marked as deleted instead, see HardRemoveByIDContext.
+func RemoveByIDContext(ctx context.Context, db ExecuterContext, v any) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	f, index, err := sch.Fields.FindPK()
-	if err != nil {
-		return err
-	}
+	return withRemoveHooks(ctx, db, v, func() error {
+		ctx := withMapping(ctx, sch)
+		field, _, err := findSoftDelete(sch)
+		if err != nil {
+			return hardRemoveByID(ctx, db, sch, v)
+		}
 
-	var (
-		val = getValueAtIndex(v, index)
-		sql = fmt.Sprintf("DELETE FROM %s WHERE %s = $1", sch.Table, f.Column)
-	)
+		d := dialectOf(db)
+		cond, vals, err := pkCondition(d, sch, v, 1)
+		if err != nil {
+			return err
+		}
 
-	return Exec(db, sql, val)
+		return ExecContext(ctx, db, softRemoveSQL(d, sch, field, "WHERE "+cond), vals...)
+	})
 }
 
 func (o *ORM) UpdateWhere(v any, sql string, args ...any) error {
-	return UpdateWhere(o.DB, v, sql, args...)
+	return UpdateWhere(o.conn(), v, sql, args...)
 }
 
 func UpdateWhere(db Executer, v any, sql string, args ...any) error {
 	return Update(db, v, "WHERE "+sql, args...)
 }
 
-func (o *ORM) Update(v any, sql string, args ...any) error {
-	return Update(o.DB, v, sql, args...)
+func (o *ORM) Update(v any, sql any, args ...any) error {
+	return Update(o.conn(), v, sql, args...)
 }
 
-func Update(db Executer, v any, sql string, args ...any) error {
-	start := len(args) + 1
+func (o *ORM) UpdateContext(ctx context.Context, v any, sql any, args ...any) error {
+	return UpdateContext(ctx, o.conn(), v, sql, args...)
+}
+
+func Update(db Executer, v any, sql any, args ...any) error {
+	return UpdateContext(context.Background(), executerContext(db), v, sql, args...)
+}
+
+// UpdateContext sets the updateable values of v on the rows matched by the sql argument, which is a string or a Clause of WHERE conditions
+func UpdateContext(ctx context.Context, db ExecuterContext, v any, sql any, args ...any) error {
+	sqlstr, args, err := whereOf(db, sql, args)
+	if err != nil {
+		return err
+	}
+
+	return withUpdateHooks(ctx, db, v, func() error {
+		return update(ctx, db, v, sqlstr, args...)
+	})
+}
+
+// update sets the values of v without running its hooks
+func update(ctx context.Context, db ExecuterContext, v any, sql string, args ...any) error {
+	var (
+		d     = dialectOf(db)
+		start = len(args) + 1
+	)
+
+	// positional placeholders are bound in order, and the assignments precede the sql argument
+	if !schema.Numbered(d) {
+		start = 1
+	}
+
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	assignments := sch.Fields.Writeable().Columns().AssignmentList(start)
-	s := fmt.Sprintf("UPDATE %s SET %s", sch.Table, assignments)
-	if sql != "" {
-		s = fmt.Sprintf("%s %s", s, sql)
+	ctx = withMapping(ctx, sch)
+
+	if err := stampUpdated(sch, v, nowOf(db)); err != nil {
+		return err
 	}
 
-	values, err := schema.Values(v)
+	cols, values, err := updateValues(sch, v, nil)
 	if err != nil {
 		return err
 	}
 
-	args = append(args, values...)
-	return Exec(db, s, args...)
+	assignments := cols.Quoted(d).AssignmentListFor(d, start)
+
+	version, vindex, verr := findVersion(sch)
+	if verr == nil {
+		assignments = versionAssignment(assignments, d.Quote(version.Column))
+		sql, err = versionClause(sql, d.Quote(version.Column), d.Placeholder(len(args)+len(values)+1))
+		if err != nil {
+			return err
+		}
+	}
+
+	s := fmt.Sprintf("UPDATE %s SET %s", d.Quote(sch.Table), assignments)
+	if sql != "" {
+		s = fmt.Sprintf("%s %s", s, sql)
+	}
+
+	if schema.Numbered(d) {
+		args = append(args, values...)
+	} else {
+		args = append(values, args...)
+	}
+
+	if verr != nil {
+		return ExecContext(ctx, db, s, args...)
+	}
+
+	args = append(args, getValueAtIndex(v, vindex))
+	return execVersioned(ctx, db, v, vindex, s, args...)
+}
+
+// UpdateByID sets values by the primary key, which may be of any type. If no primary key is found, UpdateByID returns schema.ErrFieldNotFound.
+// The columns which are set can be restricted with the Only and Omit filters.
+func (o *ORM) UpdateByID(v any, filters ...ColumnFilter) error {
+	return UpdateByID(o.conn(), v, filters...)
 }
 
-// UpdateByID sets values by the identity. If no id is found, UpdateByID return ErrNoIdentity
-func (o *ORM) UpdateByID(v any) error {
-	return UpdateByID(o.DB, v)
+// UpdateByIDContext sets values by the primary key, which may be of any type. If no primary key is found, UpdateByIDContext returns schema.ErrFieldNotFound.
+// The columns which are set can be restricted with the Only and Omit filters.
+func (o *ORM) UpdateByIDContext(ctx context.Context, v any, filters ...ColumnFilter) error {
+	return UpdateByIDContext(ctx, o.conn(), v, filters...)
 }
 
-// UpdateByID sets values by the identity. If no id is found, UpdateByID return ErrNoIdentity
-func UpdateByID(db Executer, v any) error {
+// UpdateByID sets values by the primary key, which may be of any type. If no primary key is found, UpdateByID returns schema.ErrFieldNotFound.
+// The columns which are set can be restricted with the Only and Omit filters.
+func UpdateByID(db Executer, v any, filters ...ColumnFilter) error {
+	return UpdateByIDContext(context.Background(), executerContext(db), v, filters...)
+}
+
+// UpdateByIDContext sets values by the primary key, which may be of any type. If no primary key is found, UpdateByIDContext returns schema.ErrFieldNotFound.
+// The columns which are set can be restricted with the Only and Omit filters.
+func UpdateByIDContext(ctx context.Context, db ExecuterContext, v any, filters ...ColumnFilter) error {
+	return withUpdateHooks(ctx, db, v, func() error {
+		return updateByID(ctx, db, v, filters)
+	})
+}
+
+// updateByID sets the values of v by its primary key without running its hooks
+func updateByID(ctx context.Context, db ExecuterContext, v any, filters []ColumnFilter) error {
 	sch, _, err := schema.GetMapping(v)
 	if err != nil {
 		return err
 	}
 
-	idField, indexes, err := sch.Fields.FindPK()
+	ctx = withMapping(ctx, sch)
+
+	if err := stampUpdated(sch, v, nowOf(db)); err != nil {
+		return err
+	}
+
+	cols, values, err := updateValues(sch, v, filters)
 	if err != nil {
 		return err
 	}
 
-	cols := sch.Fields.Writeable().Columns()
-	placeholders := cols.AssignmentList(1)
-	sql := fmt.Sprintf("update %s set %s", sch.Table, placeholders)
-	values, err := schema.Values(v)
+	var (
+		d            = dialectOf(db)
+		placeholders = cols.Quoted(d).AssignmentListFor(d, 1)
+	)
+
+	cond, ids, err := pkCondition(d, sch, v, len(cols)+1)
 	if err != nil {
 		return err
 	}
 
-	sv := reflect.ValueOf(v).Elem()
-	f := sv.FieldByIndex(indexes)
-	id := f.Int()
-	sql += fmt.Sprintf(" where %s = $%d", idField.Column, len(cols)+1)
-	values = append(values, id)
-	return Exec(db, sql, values...)
+	values = append(values, ids...)
+
+	version, vindex, err := findVersion(sch)
+	if err != nil {
+		sql := fmt.Sprintf("update %s set %s where %s", d.Quote(sch.Table), placeholders, cond)
+		return ExecContext(ctx, db, sql, values...)
+	}
+
+	placeholders = versionAssignment(placeholders, d.Quote(version.Column))
+	cond = fmt.Sprintf("%s AND %s = %s", cond, d.Quote(version.Column), d.Placeholder(len(values)+1))
+	values = append(values, getValueAtIndex(v, vindex))
+
+	sql := fmt.Sprintf("update %s set %s where %s", d.Quote(sch.Table), placeholders, cond)
+	return execVersioned(ctx, db, v, vindex, sql, values...)
 }
 
 func (o *ORM) CountAll(v any) (int64, error) {
-	return CountAll(o.DB, v)
+	return CountAll(o.conn(), v)
 }
 
 func CountAll(q Querier, v any) (int64, error) {
@@ -477,18 +1006,32 @@ func CountAll(q Querier, v any) (int64, error) {
 }
 
 func (o *ORM) CountWhere(v any, sql string, args ...any) (count int64, err error) {
-	return Count(o.DB, v, "WHERE "+sql, args...)
+	return Count(o.conn(), v, "WHERE "+sql, args...)
 }
 
-func (o *ORM) Count(v any, sql string, args ...any) (count int64, err error) {
-	return Count(o.DB, v, sql, args...)
+func (o *ORM) Count(v any, sql any, args ...any) (count int64, err error) {
+	return Count(o.conn(), v, sql, args...)
+}
+
+func (o *ORM) CountContext(ctx context.Context, v any, sql any, args ...any) (count int64, err error) {
+	return CountContext(ctx, o.conn(), v, sql, args...)
 }
 
 func CountWhere(q Querier, v any, sql string, args ...any) (count int64, err error) {
 	return Count(q, v, "WHERE "+sql, args...)
 }
 
-func Count(q Querier, v any, sql string, args ...any) (count int64, err error) {
+func Count(q Querier, v any, sql any, args ...any) (count int64, err error) {
+	return CountContext(context.Background(), querierContext(q), v, sql, args...)
+}
+
+// CountContext returns the number of rows matched by the sql argument, which is a string or a Clause
+func CountContext(ctx context.Context, q QuerierContext, v any, sql any, args ...any) (count int64, err error) {
+	clause, args, err := clauseOf(q, sql, args)
+	if err != nil {
+		return 0, err
+	}
+
 	var sqlstr string
 
 	if tbl, ok := v.(string); ok {
@@ -499,14 +1042,16 @@ func Count(q Querier, v any, sql string, args ...any) (count int64, err error) {
 			return 0, err
 		}
 
-		sqlstr = fmt.Sprintf("SELECT COUNT(*) FROM %s", sch.Table)
+		ctx = withMapping(ctx, sch)
+
+		sqlstr = fmt.Sprintf("SELECT COUNT(*) FROM %s", fromClause(ctx, dialectOf(q), sch))
 	}
 
-	if sql != "" {
-		sqlstr = sqlstr + " " + sql
+	if clause != "" {
+		sqlstr = sqlstr + " " + clause
 	}
 
-	row := q.QueryRow(sqlstr, args...)
+	row := q.QueryRowContext(ctx, sqlstr, args...)
 	if row == nil {
 		err = ErrNotFound
 		return
@@ -540,12 +1085,24 @@ func CollectStrings(rows Rows) ([]string, error) {
 	return strs, nil
 }
 
-// CollectRows scans a T from each row
+// CollectRows scans a T from each row.
+// Rows which implement ColumnRows are scanned by column name according to StrictColumns, others by field position
 func CollectRows[T any](rows Rows) (items []T, err error) {
 	defer rows.Close()
+
+	scan := func(v *T) error { return Scan(rows, v) }
+	if cr, ok := rows.(ColumnRows); ok {
+		fields, err := collectFields[T](cr)
+		if err != nil {
+			return nil, err
+		}
+
+		scan = func(v *T) error { return scanColumns(rows, v, fields) }
+	}
+
 	for rows.Next() {
 		var t T
-		if err := Scan(rows, &t); err != nil {
+		if err := scan(&t); err != nil {
 			return nil, err
 		}
 		items = append(items, t)
@@ -585,7 +1142,63 @@ func Scan(row Row, v any) error {
 		return err
 	}
 
-	return row.Scan(vals...)
+	if err := row.Scan(vals...); err != nil {
+		return err
+	}
+
+	return runHook(v, func(h AfterScanner) error { return h.AfterScan() })
+}
+
+// pkCondition returns a condition matching every primary key column of v along with the values to bind.
+// Placeholders are numbered from start.
+func pkCondition(d Dialect, mapping *schema.StructMapping, v any, start int) (string, []any, error) {
+	fields, indexes, err := mapping.Fields.FindPKs()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		parts []string
+		vals  []any
+	)
+
+	for i, f := range fields {
+		parts = append(parts, fmt.Sprintf("%s = %s", d.Quote(f.Column), d.Placeholder(start+i)))
+		vals = append(vals, getValueAtIndex(v, indexes[i]))
+	}
+
+	return strings.Join(parts, " AND "), vals, nil
+}
+
+// findGeneratedPK returns the primary key which is generated by the database.
+// Primary keys which are written by the application, such as the columns of a composite key, are not considered.
+func findGeneratedPK(mapping *schema.StructMapping) (*schema.FieldMapping, []int, error) {
+	return mapping.Fields.Find(func(f *schema.FieldMapping) bool {
+		return f.IsPrimaryKey && f.IsReadOnly
+	})
+}
+
+// setLastInsertID sets the id reported by res on field.
+// Fields which are not integers are left untouched as the id could not have been generated by the database.
+func setLastInsertID(res sql.Result, field reflect.Value) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(uint64(id))
+	}
+
+	return nil
 }
 
 // gets the address of the struct value at a given index
@@ -593,7 +1206,15 @@ func getAddrAtIndex(v any, index []int) interface{} {
 	return reflect.ValueOf(v).Elem().FieldByIndex(index).Addr().Interface()
 }
 
-// gets the concrete value at given index
+// gets the concrete value at given index.
+// The address is returned instead when only a pointer to the value implements driver.Valuer
 func getValueAtIndex(v any, index []int) interface{} {
-	return reflect.ValueOf(v).Elem().FieldByIndex(index).Interface()
+	field := reflect.ValueOf(v).Elem().FieldByIndex(index)
+	if _, ok := field.Interface().(driver.Valuer); !ok && field.CanAddr() {
+		if valuer, ok := field.Addr().Interface().(driver.Valuer); ok {
+			return valuer
+		}
+	}
+
+	return field.Interface()
 }
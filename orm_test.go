@@ -1,11 +1,24 @@
 package orm_test
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cristosal/orm"
+	"github.com/cristosal/orm/ormtest"
+	"github.com/cristosal/orm/query"
 	"github.com/cristosal/orm/schema"
 )
 
@@ -21,82 +34,1519 @@ func TestAddMany(t *testing.T) {
 	}
 
 	var (
-		mockdb = &mockDB{}
-		db     = orm.New(mockdb)
-		users  = []User{
+		mockdb = &mockDB{
+			Columns: []string{"id"},
+			Rows:    [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+		}
+		db    = orm.New(mockdb)
+		users = []User{
+			{Email: "user1@example.com", Password: "password"},
+			{Email: "user2@example.com", Password: "password"},
+			{Email: "user3@example.com", Password: "password"},
+		}
+	)
+
+	if err := db.AddMany(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "user" ("email", "password") VALUES ($1, $2), ($3, $4), ($5, $6) returning "id"`)
+	mockdb.ExpectValueAt(t, 0, "user1@example.com")
+	mockdb.ExpectValueAt(t, 1, "password")
+	mockdb.ExpectValueAt(t, 2, "user2@example.com")
+	mockdb.ExpectValueAt(t, 3, "password")
+
+	for i, u := range users {
+		if u.ID != int64(i+1) {
+			t.Fatalf("expected user %d to have id %d, got %d", i, i+1, u.ID)
+		}
+	}
+}
+
+// smallDialect limits statements to 4 parameters
+type smallDialect struct{ orm.Dialect }
+
+func (smallDialect) MaxParams() int { return 4 }
+
+func TestAddManyBatches(t *testing.T) {
+	type User struct {
+		ID       int64
+		Email    string
+		Password string
+	}
+
+	var (
+		mockdb = &mockDB{
+			Columns: []string{"id"},
+			Rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+		}
+		db    = orm.New(mockdb, orm.WithDialect(smallDialect{orm.Postgres}))
+		users = []User{
 			{Email: "user1@example.com", Password: "password"},
 			{Email: "user2@example.com", Password: "password"},
 			{Email: "user3@example.com", Password: "password"},
 		}
 	)
 
-	if err := db.AddMany(users); err != nil {
+	if err := db.AddMany(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"BEGIN",
+		`INSERT INTO "user" ("email", "password") VALUES ($1, $2), ($3, $4) returning "id"`,
+		`INSERT INTO "user" ("email", "password") VALUES ($1, $2) returning "id"`,
+		"COMMIT",
+	}
+
+	if len(mockdb.Statements) != len(expected) {
+		t.Fatalf("expected %d statements within the transaction, got %v", len(expected), mockdb.Statements)
+	}
+
+	for i := range expected {
+		if mockdb.Statements[i] != expected[i] {
+			t.Fatalf("expected:\n%s\n\ngot:\n%s", expected[i], mockdb.Statements[i])
+		}
+	}
+
+	if users[1].ID != 2 || users[2].ID != 1 {
+		t.Fatalf("expected ids to be scanned per batch, got %+v", users)
+	}
+
+	type Wide struct {
+		A, B, C, D, E string
+	}
+
+	if err := db.AddMany(&[]Wide{{}, {}}); !errors.Is(err, orm.ErrTooManyParams) {
+		t.Fatalf("expected ErrTooManyParams, got %v", err)
+	}
+}
+
+func TestDbWrapper(t *testing.T) {
+	db := orm.New(&mockDB{})
+
+	type Users struct {
+		ID    int64
+		Email string
+	}
+
+	var users []Users
+
+	db.Add(&Users{
+		Email: "johndoe@gmail.com",
+	})
+
+	// don't know if we can actually test this
+	if err := db.ListAll(&users); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListAny(t *testing.T) {
+	db := mockDB{}
+
+	type A struct {
+		ID       int64
+		Username string
+		Password string
+	}
+
+	var a A
+
+	a.Username = "foo"
+	a.Password = "bar"
+
+	var as []A
+
+	orm.List(&db, &as, "WHERE username = $1", a.Username)
+	db.ExpectSQL(t, `SELECT "id", "username", "password" FROM "a" WHERE username = $1`)
+}
+
+func TestUpdate(t *testing.T) {
+	db := mockDB{}
+	type A struct {
+		ID       int64
+		Username string
+		Password string
+	}
+	var a A
+
+	a.Username = "foo"
+	a.Password = "bar"
+	orm.Update(&db, &a, "WHERE username = $1", a.Username)
+	db.ExpectSQL(t, `UPDATE "a" SET "username" = $2, "password" = $3 WHERE username = $1`)
+	db.ExpectValueAt(t, 0, a.Username)
+	db.ExpectValueAt(t, 1, a.Username)
+	db.ExpectValueAt(t, 2, a.Password)
+}
+
+func TestUpdateMySQL(t *testing.T) {
+	type A struct {
+		ID       int64
+		Username string
+		Password string
+	}
+
+	mockdb := &mockDB{}
+	db := orm.New(mockdb, orm.WithDialect(orm.MySQL))
+	a := A{Username: "foo", Password: "bar"}
+	db.Update(&a, "WHERE username = ?", "baz")
+	mockdb.ExpectSQL(t, "UPDATE `a` SET `username` = ?, `password` = ? WHERE username = ?")
+	mockdb.ExpectValueAt(t, 0, a.Username)
+	mockdb.ExpectValueAt(t, 1, a.Password)
+	mockdb.ExpectValueAt(t, 2, "baz")
+}
+
+func TestAddLastInsertID(t *testing.T) {
+	type Account struct {
+		ID       int64
+		Username string
+	}
+
+	mockdb := &mockDB{}
+	db := orm.New(mockdb, orm.WithDialect(orm.MySQL))
+	a := Account{Username: "foo"}
+	if err := db.Add(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "INSERT INTO `account` (`username`) VALUES (?)")
+	if a.ID != 1 {
+		t.Fatalf("expected id to be set from LastInsertId, got %d", a.ID)
+	}
+}
+
+func TestGetByIDSQLite(t *testing.T) {
+	type Account struct {
+		ID       int64
+		Username string
+	}
+
+	mockdb := &mockDB{}
+	db := orm.New(mockdb, orm.WithDialect(orm.SQLite))
+	db.GetByID(&Account{ID: 2})
+	mockdb.ExpectSQL(t, `SELECT "id", "username" FROM "account" WHERE "id" = ?1`)
+	mockdb.ExpectValueAt(t, 0, int64(2))
+}
+
+func TestUpsert(t *testing.T) {
+	type Subscriber struct {
+		ID    int64
+		Email string
+		Name  string
+	}
+
+	sub := Subscriber{ID: 1, Email: "john@example.com", Name: "John"}
+
+	tt := []struct {
+		opts     *orm.UpsertOptions
+		dialect  orm.Dialect
+		expected string
+	}{
+		{nil, orm.Postgres, `INSERT INTO "subscriber" ("id", "email", "name") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email", "name" = EXCLUDED."name"`},
+		{&orm.UpsertOptions{Conflict: []string{"email"}, Update: []string{"name"}}, orm.Postgres, `INSERT INTO "subscriber" ("email", "name") VALUES ($1, $2) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{&orm.UpsertOptions{Conflict: []string{"email"}, DoNothing: true}, orm.SQLite, `INSERT INTO "subscriber" ("email", "name") VALUES (?1, ?2) ON CONFLICT ("email") DO NOTHING`},
+		{nil, orm.MySQL, "INSERT INTO `subscriber` (`id`, `email`, `name`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`), `name` = VALUES(`name`)"},
+	}
+
+	for _, tc := range tt {
+		mockdb := &mockDB{}
+		if err := orm.New(mockdb, orm.WithDialect(tc.dialect)).Upsert(&sub, tc.opts); err != nil {
+			t.Fatal(err)
+		}
+
+		mockdb.ExpectSQL(t, tc.expected)
+	}
+}
+
+func TestUpsertWithoutTarget(t *testing.T) {
+	type Signup struct {
+		Email string
+	}
+
+	mockdb := &mockDB{}
+	if err := orm.New(mockdb).Upsert(&Signup{Email: "john@example.com"}, &orm.UpsertOptions{DoNothing: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "signup" ("email") VALUES ($1) ON CONFLICT DO NOTHING`)
+
+	mockdb = &mockDB{}
+	err := orm.New(mockdb, orm.WithDialect(orm.MySQL)).Upsert(&Signup{Email: "john@example.com"}, &orm.UpsertOptions{DoNothing: true})
+	if !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected mysql upsert without a conflict target to be rejected, got %v", err)
+	}
+
+	if mockdb.SQL != "" {
+		t.Fatalf("expected no statement, got %s", mockdb.SQL)
+	}
+}
+
+func TestUpsertReturning(t *testing.T) {
+	type Subscriber struct {
+		ID    int64
+		Email string
+		Name  string
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"id", "email", "name"},
+		Rows:    [][]driver.Value{{int64(7), "john@example.com", "John Doe"}},
+	}
+
+	sub := Subscriber{Email: "john@example.com", Name: "John"}
+	err := orm.Upsert(mockdb, &sub, &orm.UpsertOptions{Conflict: []string{"email"}, Returning: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "subscriber" ("email", "name") VALUES ($1, $2) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id", "email", "name"`)
+	if sub.ID != 7 || sub.Name != "John Doe" {
+		t.Fatalf("expected returned row to be scanned, got %+v", sub)
+	}
+}
+
+func TestUpsertInvalidColumn(t *testing.T) {
+	type Subscriber struct {
+		ID    int64
+		Email string
+		Name  string
+	}
+
+	err := orm.Upsert(&mockDB{}, &Subscriber{}, &orm.UpsertOptions{Conflict: []string{"missing"}})
+	if !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected ErrFieldNotFound, got %v", err)
+	}
+}
+
+func TestUpsertMany(t *testing.T) {
+	type Subscriber struct {
+		ID    int64
+		Email string
+		Name  string
+	}
+
+	mockdb := &mockDB{}
+	subs := []Subscriber{{Email: "a@example.com", Name: "A"}, {Email: "b@example.com", Name: "B"}}
+	if err := orm.UpsertMany(mockdb, subs, &orm.UpsertOptions{Conflict: []string{"email"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "subscriber" ("email", "name") VALUES ($1, $2), ($3, $4) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`)
+	mockdb.ExpectValueAt(t, 2, "b@example.com")
+}
+
+func TestCompositePK(t *testing.T) {
+	type Membership struct {
+		TenantID int64 `db:"tenant_id,pk"`
+		UserID   int64 `db:"user_id,pk"`
+		Role     string
+	}
+
+	m := Membership{TenantID: 1, UserID: 2, Role: "admin"}
+	mockdb := &mockDB{}
+
+	orm.GetByID(mockdb, &m)
+	mockdb.ExpectSQL(t, `SELECT "tenant_id", "user_id", "role" FROM "membership" WHERE "tenant_id" = $1 AND "user_id" = $2`)
+	mockdb.ExpectValueAt(t, 0, int64(1))
+	mockdb.ExpectValueAt(t, 1, int64(2))
+
+	orm.RemoveByID(mockdb, &m)
+	mockdb.ExpectSQL(t, `DELETE FROM "membership" WHERE "tenant_id" = $1 AND "user_id" = $2`)
+
+	orm.UpdateByID(mockdb, &m)
+	mockdb.ExpectSQL(t, `update "membership" set "role" = $1 where "tenant_id" = $2 AND "user_id" = $3`)
+	mockdb.ExpectValueAt(t, 2, int64(2))
+
+	if err := orm.UpdateColumns(mockdb, &m, "tenant_id"); !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected primary key column to be rejected, got %v", err)
+	}
+
+	orm.Add(mockdb, &m)
+	mockdb.ExpectSQL(t, `INSERT INTO "membership" ("tenant_id", "user_id", "role") VALUES ($1, $2, $3)`)
+}
+
+// testUUID is a uuid-like key which implements driver.Valuer
+type testUUID [16]byte
+
+func (u testUUID) Value() (driver.Value, error) { return u[:], nil }
+
+// ptrKey implements driver.Valuer on its pointer only
+type ptrKey struct{ code string }
+
+func (k *ptrKey) Value() (driver.Value, error) { return k.code, nil }
+
+func TestUpdateByIDKeyTypes(t *testing.T) {
+	type StringKey struct {
+		ID   string
+		Name string
+	}
+
+	type UUIDKey struct {
+		ID   testUUID
+		Name string
+	}
+
+	type Int64Key struct {
+		ID   int64
+		Name string
+	}
+
+	type PtrValuerKey struct {
+		ID   ptrKey
+		Name string
+	}
+
+	var (
+		s = StringKey{ID: "abc", Name: "string"}
+		u = UUIDKey{ID: testUUID{1, 2, 3}, Name: "uuid"}
+		i = Int64Key{ID: 42, Name: "int64"}
+		p = PtrValuerKey{ID: ptrKey{"xyz"}, Name: "valuer"}
+	)
+
+	tt := []struct {
+		record   any
+		expected string
+		id       any
+	}{
+		{&s, `update "string_key" set "name" = $1 where "id" = $2`, "abc"},
+		{&u, `update "uuid_key" set "name" = $1 where "id" = $2`, u.ID},
+		{&i, `update "int64_key" set "name" = $1 where "id" = $2`, int64(42)},
+		{&p, `update "ptr_valuer_key" set "name" = $1 where "id" = $2`, &p.ID},
+	}
+
+	for _, tc := range tt {
+		mockdb := &mockDB{}
+		if err := orm.UpdateByID(mockdb, tc.record); err != nil {
+			t.Fatal(err)
+		}
+
+		mockdb.ExpectSQL(t, tc.expected)
+		mockdb.ExpectValueAt(t, 1, tc.id)
+	}
+}
+
+func TestUpdateByIDNestedPK(t *testing.T) {
+	type Base struct {
+		ID string
+	}
+
+	type Audited struct {
+		Base
+		Author string
+	}
+
+	type Note struct {
+		Audited
+		Body string
+	}
+
+	mockdb := &mockDB{}
+	n := Note{Audited: Audited{Base: Base{ID: "n1"}, Author: "jane"}, Body: "hello"}
+	if err := orm.UpdateByID(mockdb, &n); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "note" set "author" = $1, "body" = $2 where "id" = $3`)
+	mockdb.ExpectValueAt(t, 2, "n1")
+}
+
+func TestUpdateColumns(t *testing.T) {
+	type Profile struct {
+		ID    int64
+		Name  string
+		Email string
+		Bio   string
+	}
+
+	p := Profile{ID: 3, Name: "jane", Email: "jane@example.com", Bio: "hi"}
+	mockdb := &mockDB{}
+
+	if err := orm.UpdateColumns(mockdb, &p, "bio", "name"); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "profile" set "name" = $1, "bio" = $2 where "id" = $3`)
+	mockdb.ExpectValueAt(t, 0, "jane")
+	mockdb.ExpectValueAt(t, 1, "hi")
+	mockdb.ExpectValueAt(t, 2, int64(3))
+
+	if err := orm.UpdateByID(mockdb, &p, orm.Omit("email")); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "profile" set "name" = $1, "bio" = $2 where "id" = $3`)
+
+	if err := orm.UpdateColumns(mockdb, &p, "missing"); !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected ErrFieldNotFound, got %v", err)
+	}
+
+	if err := orm.UpdateColumns(mockdb, &p, "id"); !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected readonly column to be rejected, got %v", err)
+	}
+
+	if err := orm.UpdateColumns(mockdb, &p); !errors.Is(err, orm.ErrNoColumns) {
+		t.Fatalf("expected ErrNoColumns, got %v", err)
+	}
+}
+
+func TestTrackedSave(t *testing.T) {
+	type Customer struct {
+		ID    int64
+		Name  string
+		Email string
+		Tags  []byte
+	}
+
+	c := Customer{ID: 9, Name: "jane", Email: "jane@example.com", Tags: []byte("a")}
+	tracked, err := orm.Track(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb := &mockDB{}
+	if err := tracked.Save(mockdb); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "")
+
+	c.Email = "jane@example.org"
+	c.Tags[0] = 'b'
+	if err := tracked.Save(mockdb); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "customer" set "email" = $1, "tags" = $2 where "id" = $3`)
+	mockdb.ExpectValueAt(t, 0, "jane@example.org")
+
+	// saving again is a no-op as the snapshot was reset
+	mockdb.SQL = ""
+	if err := tracked.Save(mockdb); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "")
+}
+
+func TestGetTracked(t *testing.T) {
+	type Customer struct {
+		ID    int64
+		Name  string
+		Email string
+		Tags  []byte
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"id", "name", "email", "tags"},
+		Rows:    [][]driver.Value{{int64(1), "jane", "jane@example.com", []byte("a")}},
+	}
+
+	tracked, err := orm.GetTracked[Customer](mockdb, "WHERE id = $1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracked.Value.Name = "janet"
+	changed, err := tracked.Changed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changed) != 1 || changed[0] != "name" {
+		t.Fatalf("expected only name to have changed, got %v", changed)
+	}
+}
+
+func TestUpdateVersioned(t *testing.T) {
+	type Document struct {
+		ID      int64
+		Title   string
+		Version int64 `db:"version,version"`
+	}
+
+	mockdb := &mockDB{}
+	doc := Document{ID: 4, Title: "draft", Version: 2}
+
+	if err := orm.UpdateByID(mockdb, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "document" set "title" = $1, "version" = "version" + 1 where "id" = $2 AND "version" = $3`)
+	mockdb.ExpectValueAt(t, 2, int64(2))
+
+	if doc.Version != 3 {
+		t.Fatalf("expected version to be incremented to 3, got %d", doc.Version)
+	}
+
+	if err := orm.Update(mockdb, &doc, "WHERE title = $1 OR id = $2", "draft", 4); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "document" SET "title" = $3, "version" = "version" + 1 WHERE (title = $1 OR id = $2) AND "version" = $4`)
+	mockdb.ExpectValueAt(t, 3, int64(3))
+
+	mockdb.NoRows = true
+	if err := orm.UpdateByID(mockdb, &doc); !errors.Is(err, orm.ErrStaleRecord) {
+		t.Fatalf("expected ErrStaleRecord, got %v", err)
+	}
+
+	if doc.Version != 4 {
+		t.Fatalf("expected version to be left at 4 for stale record, got %d", doc.Version)
+	}
+
+	if err := orm.Update(mockdb, &doc, "LIMIT 1"); !errors.Is(err, orm.ErrVersionClause) {
+		t.Fatalf("expected ErrVersionClause, got %v", err)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	type Patron struct {
+		ID        int64
+		Name      string
+		DeletedAt *time.Time `db:"deleted_at,softdelete"`
+	}
+
+	mockdb := &mockDB{}
+	ctx := context.Background()
+	patron := Patron{ID: 5}
+
+	if err := orm.RemoveByID(mockdb, &patron); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "patron" SET "deleted_at" = CURRENT_TIMESTAMP WHERE "id" = $1`)
+	mockdb.ExpectValueAt(t, 0, int64(5))
+
+	if err := orm.Remove(mockdb, &patron, "WHERE name = $1", "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "patron" SET "deleted_at" = CURRENT_TIMESTAMP WHERE name = $1`)
+
+	if err := orm.HardRemoveByID(mockdb, &patron); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `DELETE FROM "patron" WHERE "id" = $1`)
+
+	orm.Get(mockdb, &patron, "WHERE name = $1 ORDER BY id", "bob")
+	mockdb.ExpectSQL(t, `SELECT "id", "name", "deleted_at" FROM (SELECT * FROM "patron" WHERE "deleted_at" IS NULL) AS "patron" WHERE name = $1 ORDER BY id`)
+
+	var patrons []Patron
+	db := orm.New(mockdb)
+	db.ListContext(orm.OnlyDeleted(ctx), &patrons, "")
+	mockdb.ExpectSQL(t, `SELECT "id", "name", "deleted_at" FROM (SELECT * FROM "patron" WHERE "deleted_at" IS NOT NULL) AS "patron"`)
+
+	db.CountContext(orm.WithDeleted(ctx), &patron, "")
+	mockdb.ExpectSQL(t, `SELECT COUNT(*) FROM "patron"`)
+
+	// updating a stale record must not undelete it
+	patron.Name = "bob"
+	if err := orm.UpdateByID(mockdb, &patron); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "patron" set "name" = $1 where "id" = $2`)
+
+	if err := orm.Update(mockdb, &patron, "WHERE name = $1", "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "patron" SET "name" = $2 WHERE name = $1`)
+
+	if err := orm.UpdateColumns(mockdb, &patron, "deleted_at"); !errors.Is(err, schema.ErrFieldNotFound) {
+		t.Fatalf("expected softdelete column to be rejected, got %v", err)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	type Invoice struct {
+		ID        int64
+		Number    string
+		CreatedAt time.Time    `db:"created_at,autocreate"`
+		UpdatedAt sql.NullTime `db:"updated_at,autoupdate"`
+	}
+
+	var (
+		now    = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockdb = &mockDB{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}}}
+		db     = orm.New(mockdb, orm.WithClock(func() time.Time { return now }))
+		inv    = Invoice{Number: "A-1"}
+	)
+
+	if err := db.Add(&inv); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "invoice" ("number", "created_at", "updated_at") VALUES ($1, $2, $3) returning "id"`)
+	mockdb.ExpectValueAt(t, 1, now)
+
+	if !inv.CreatedAt.Equal(now) || !inv.UpdatedAt.Valid || !inv.UpdatedAt.Time.Equal(now) {
+		t.Fatalf("expected timestamps to be written back, got %+v", inv)
+	}
+
+	created := now
+	now = now.Add(time.Hour)
+
+	if err := db.UpdateColumns(&inv, "number"); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "invoice" set "number" = $1, "updated_at" = $2 where "id" = $3`)
+
+	if !inv.CreatedAt.Equal(created) || !inv.UpdatedAt.Time.Equal(now) {
+		t.Fatalf("expected only updated_at to change, got %+v", inv)
+	}
+
+	upserted := Invoice{ID: 2, Number: "A-2"}
+	if err := db.Upsert(&upserted, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "invoice" ("id", "number", "created_at", "updated_at") VALUES ($1, $2, $3, $4) ON CONFLICT ("id") DO UPDATE SET "number" = EXCLUDED."number", "updated_at" = EXCLUDED."updated_at"`)
+	mockdb.ExpectValueAt(t, 2, now)
+
+	if !upserted.CreatedAt.Equal(now) || !upserted.UpdatedAt.Time.Equal(now) {
+		t.Fatalf("expected timestamps to be written back, got %+v", upserted)
+	}
+
+	invoices := []Invoice{{Number: "A-3"}, {Number: "A-4"}}
+	if err := db.UpsertMany(invoices, &orm.UpsertOptions{Conflict: []string{"number"}, Update: []string{"number"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `INSERT INTO "invoice" ("number", "created_at", "updated_at") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("number") DO UPDATE SET "number" = EXCLUDED."number", "updated_at" = EXCLUDED."updated_at"`)
+	mockdb.ExpectValueAt(t, 4, now)
+}
+
+var errInvalidEmail = errors.New("invalid email")
+
+type hookedMember struct {
+	ID      int64
+	Email   string
+	Scanned bool `db:"-"`
+}
+
+func (m *hookedMember) BeforeAdd(ctx context.Context, db orm.ExecuterContext) error {
+	m.Email = strings.ToLower(m.Email)
+	return nil
+}
+
+func (m *hookedMember) AfterAdd(ctx context.Context, db orm.ExecuterContext) error {
+	return orm.ExecContext(ctx, db, "INSERT INTO audit (member_id) VALUES ($1)", m.ID)
+}
+
+func (m *hookedMember) BeforeUpdate(ctx context.Context, db orm.ExecuterContext) error {
+	if !strings.Contains(m.Email, "@") {
+		return errInvalidEmail
+	}
+
+	return nil
+}
+
+func (m *hookedMember) AfterScan() error {
+	m.Scanned = true
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	mockdb := &mockDB{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(3)}}}
+	m := hookedMember{Email: "A@Example.com"}
+
+	if err := orm.Add(mockdb, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Email != "a@example.com" {
+		t.Fatalf("expected email to be normalized before add, got %s", m.Email)
+	}
+
+	mockdb.ExpectSQL(t, "INSERT INTO audit (member_id) VALUES ($1)")
+	mockdb.ExpectValueAt(t, 0, int64(3))
+
+	m.Email = "invalid"
+	mockdb.SQL = ""
+	if err := orm.UpdateByID(mockdb, &m); !errors.Is(err, errInvalidEmail) {
+		t.Fatalf("expected errInvalidEmail, got %v", err)
+	}
+
+	mockdb.ExpectSQL(t, "")
+
+	mockdb.Columns = []string{"id", "email"}
+	mockdb.Rows = [][]driver.Value{{int64(3), "a@example.com"}}
+
+	var found hookedMember
+	if err := orm.Get(mockdb, &found, "WHERE id = $1", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if !found.Scanned {
+		t.Fatal("expected AfterScan to be called")
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	type Ticket struct {
+		ID    int64
+		Title string
+	}
+
+	var (
+		errBlocked = errors.New("blocked")
+		seen       []orm.Statement
+		mockdb     = &mockDB{Columns: []string{"id", "title"}, Rows: [][]driver.Value{{int64(1), "first"}}}
+	)
+
+	record := func(ctx context.Context, stmt *orm.Statement, next orm.Handler) (orm.Response, error) {
+		res, err := next(ctx, stmt)
+		seen = append(seen, *stmt)
+		return res, err
+	}
+
+	rewrite := func(ctx context.Context, stmt *orm.Statement, next orm.Handler) (orm.Response, error) {
+		if strings.HasPrefix(stmt.SQL, "DELETE") || strings.HasPrefix(stmt.SQL, "SELECT COUNT") {
+			return orm.Response{}, errBlocked
+		}
+
+		stmt.SQL += " -- app"
+		return next(ctx, stmt)
+	}
+
+	db := orm.New(mockdb, orm.WithInterceptors(record, rewrite))
+
+	var ticket Ticket
+	if err := db.GetByID(&Ticket{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "title" FROM "ticket" WHERE "id" = $1 -- app`)
+
+	if len(seen) != 1 || seen[0].Op != orm.OpQueryRow || seen[0].Mapping == nil || seen[0].Mapping.Table != "ticket" {
+		t.Fatalf("expected query row on ticket to be intercepted, got %+v", seen)
+	}
+
+	if err := db.RemoveByID(&ticket); !errors.Is(err, errBlocked) {
+		t.Fatalf("expected errBlocked, got %v", err)
+	}
+
+	var tickets []Ticket
+	_, err := orm.Paginate(db.Conn(), &tickets, &orm.PaginationOptions{Page: 1, PageSize: 10})
+	if !errors.Is(err, errBlocked) {
+		t.Fatalf("expected errBlocked from short-circuited count, got %v", err)
+	}
+
+	if n := len(seen); n != 3 || !strings.HasPrefix(seen[n-1].SQL, `SELECT COUNT(*) FROM "ticket"`) {
+		t.Fatalf("expected paginate to be intercepted, got %+v", seen)
+	}
+}
+
+func TestMigrateInterceptors(t *testing.T) {
+	var (
+		seen   []string
+		mockdb = &mockDB{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(1)}}}
+	)
+
+	db := orm.New(mockdb, orm.WithInterceptors(func(ctx context.Context, stmt *orm.Statement, next orm.Handler) (orm.Response, error) {
+		seen = append(seen, stmt.SQL)
+		return next(ctx, stmt)
+	}))
+
+	err := db.Migrate("create widget", func(tx *sql.Tx) error {
+		_, err := tx.Exec("CREATE TABLE widget (id INTEGER)")
+		return err
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Contains(mockdb.Statements, "CREATE TABLE widget (id INTEGER)") {
+		t.Fatalf("expected the migration to be executed, got %q", mockdb.Statements)
+	}
+
+	// the migration runs on the raw transaction, only its bookkeeping is intercepted
+	expected := []string{
+		`SELECT "id", "name" FROM "_migrations" WHERE name = $1`,
+		`INSERT INTO "_migrations" ("name") VALUES ($1) returning "id"`,
+	}
+
+	if strings.Join(seen, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected intercepted statements %q", seen)
+	}
+}
+
+func TestLogInterceptor(t *testing.T) {
+	type Ledger struct {
+		ID     int64
+		Amount int64
+	}
+
+	var (
+		buf    bytes.Buffer
+		logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		mockdb = &mockDB{}
+	)
+
+	db := orm.New(mockdb, orm.WithLogger(logger, nil))
+	if err := db.UpdateByID(&Ledger{ID: 1, Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry struct {
+		Level        string
+		SQL          string
+		Args         []any
+		Table        string
+		RowsAffected int64 `json:"rows_affected"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Level != "DEBUG" || entry.SQL != `update "ledger" set "amount" = $1 where "id" = $2` || entry.Table != "ledger" || entry.RowsAffected != 1 {
+		t.Fatalf("unexpected log entry %+v", entry)
+	}
+
+	if len(entry.Args) != 2 || entry.Args[0] != "int64" {
+		t.Fatalf("expected args to be redacted, got %v", entry.Args)
+	}
+
+	buf.Reset()
+	db = orm.New(mockdb, orm.WithLogger(logger, &orm.LogOptions{SlowThreshold: time.Nanosecond}))
+	if err := db.Exec("SELECT pg_sleep(1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"level":"WARN"`) {
+		t.Fatalf("expected slow statement to be logged at warn, got %s", buf.String())
+	}
+}
+
+func TestTraceInterceptor(t *testing.T) {
+	type Shipment struct {
+		ID      int64
+		Carrier string
+	}
+
+	var (
+		recorder = ormtest.NewRecorder()
+		mockdb   = &mockDB{Columns: []string{"id", "carrier"}, Rows: [][]driver.Value{{int64(1), "ups"}}}
+		db       = orm.New(mockdb, orm.WithDialect(orm.SQLite), orm.WithTracer(recorder))
+	)
+
+	if err := db.GetByID(&Shipment{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Exec("VACUUM"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "SELECT shipment" || spans[0].System != "sqlite" || spans[0].Statement != `SELECT "id", "carrier" FROM "shipment" WHERE "id" = ?1` {
+		t.Fatalf("unexpected span %+v", spans[0])
+	}
+
+	if spans[1].Name() != "VACUUM" || spans[1].Table != "" || spans[1].Err != nil {
+		t.Fatalf("unexpected span %+v", spans[1])
+	}
+}
+
+func TestTx(t *testing.T) {
+	var (
+		mockdb   = &mockDB{}
+		db       = orm.New(mockdb)
+		errInner = errors.New("inner")
+	)
+
+	err := db.Tx(func(tx *orm.ORM) error {
+		if err := tx.Exec("UPDATE a SET n = 1"); err != nil {
+			return err
+		}
+
+		if err := tx.Tx(func(inner *orm.ORM) error {
+			inner.Exec("UPDATE b SET n = 1")
+			return errInner
+		}); !errors.Is(err, errInner) {
+			t.Fatalf("expected errInner from savepoint, got %v", err)
+		}
+
+		return tx.Tx(func(inner *orm.ORM) error {
+			return inner.Exec("UPDATE c SET n = 1")
+		})
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"BEGIN",
+		"UPDATE a SET n = 1",
+		"SAVEPOINT sp_1",
+		"UPDATE b SET n = 1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_1",
+		"UPDATE c SET n = 1",
+		"RELEASE SAVEPOINT sp_1",
+		"COMMIT",
+	}
+
+	if strings.Join(mockdb.Statements, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected statements %q", mockdb.Statements)
+	}
+
+	mockdb.Statements = nil
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to be propagated")
+			}
+		}()
+
+		db.Tx(func(tx *orm.ORM) error { panic("boom") })
+	}()
+
+	if last := mockdb.Statements[len(mockdb.Statements)-1]; last != "ROLLBACK" {
+		t.Fatalf("expected rollback after panic, got %q", mockdb.Statements)
+	}
+}
+
+// sqlStateError mimics driver errors which report their SQLSTATE code
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestRetryTx(t *testing.T) {
+	var (
+		mockdb   = &mockDB{}
+		db       = orm.New(mockdb)
+		attempts = 0
+		policy   = &orm.RetryPolicy{MaxAttempts: 3, Jitter: 1}
+	)
+
+	err := db.RetryTx(policy, func(tx *orm.ORM) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("update: %w", sqlStateError("40001"))
+		}
+
+		return tx.Exec("UPDATE a SET n = n + 1")
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+
+	expected := "BEGIN ROLLBACK BEGIN ROLLBACK BEGIN UPDATE a SET n = n + 1 COMMIT"
+	if got := strings.Join(mockdb.Statements, " "); got != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, got)
+	}
+
+	attempts = 0
+	err = db.RetryTx(policy, func(tx *orm.ORM) error {
+		attempts++
+		return sqlStateError("23505")
+	})
+
+	if attempts != 1 || !errors.Is(err, sqlStateError("23505")) {
+		t.Fatalf("expected unique violation to fail without retrying, got %d attempts and %v", attempts, err)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	type Product struct {
+		ID    int64
+		Title string
+	}
+
+	mockdb := &mockDB{Columns: []string{"id", "title"}, Rows: [][]driver.Value{{int64(9), "lamp"}}}
+
+	p, err := orm.Find[Product](mockdb, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "title" FROM "product" WHERE "id" = $1`)
+	mockdb.ExpectValueAt(t, 0, int64(9))
+
+	if p.Title != "lamp" {
+		t.Fatalf("expected product to be scanned, got %+v", p)
+	}
+
+	if _, err := orm.Find[Product](mockdb, "nine"); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for a string id, got %v", err)
+	}
+
+	type Gadget struct {
+		ID   int8
+		Code [4]byte `db:"code,pk"`
+	}
+
+	for _, ids := range [][]any{{300, [4]byte{}}, {-1, []byte{1, 2}}, {uint64(math.MaxUint64), [4]byte{}}} {
+		if _, err := orm.Find[Gadget](mockdb, ids...); !errors.Is(err, orm.ErrInvalidType) {
+			t.Fatalf("expected ErrInvalidType for %v, got %v", ids, err)
+		}
+	}
+
+	mockdb.Rows = nil
+	if _, err := orm.Find[Gadget](mockdb, 7, []byte{1, 2, 3, 4}); !errors.Is(err, orm.ErrNotFound) {
+		t.Fatalf("expected key of matching length to be queried, got %v", err)
+	}
+
+	mockdb.ExpectValueAt(t, 0, int8(7))
+	mockdb.Rows = [][]driver.Value{{int64(9), "lamp"}}
+
+	products, err := orm.ListOf[Product](mockdb, "WHERE title = $1", "lamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(products) != 1 || products[0].ID != 9 {
+		t.Fatalf("expected one product, got %+v", products)
+	}
+
+	repo, err := orm.NewRepository[Product](mockdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repo.Mapping().Table != "product" {
+		t.Fatalf("expected mapping of product, got %s", repo.Mapping().Table)
+	}
+
+	p.Title = "desk lamp"
+	if err := repo.Update(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `update "product" set "title" = $1 where "id" = $2`)
+}
+
+func TestIter(t *testing.T) {
+	type Event struct {
+		ID   int64
+		Name string
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"id", "name"},
+		Rows:    [][]driver.Value{{int64(1), "start"}, {int64(2), "stop"}},
+	}
+
+	cursor, err := orm.Iter[Event](mockdb, "ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer cursor.Close()
+
+	mockdb.ExpectSQL(t, `SELECT "id", "name" FROM "event" ORDER BY id`)
+
+	var names []string
+	for cursor.Next() {
+		names = append(names, cursor.Value().Name)
+	}
+
+	if err := cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(names, ",") != "start,stop" {
+		t.Fatalf("expected both rows to be iterated, got %v", names)
+	}
+}
+
+func TestIterBindsArgs(t *testing.T) {
+	type Event struct {
+		ID   int64
+		Name string
+	}
+
+	mockdb := &mockDB{}
+	cursor, err := orm.Iter[Event](mockdb, "WHERE id IN (:ids) AND name = :name", map[string]any{"ids": orm.In([]int64{1, 2}), "name": "start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor.Close()
+	mockdb.ExpectSQL(t, `SELECT "id", "name" FROM "event" WHERE id IN ($1, $2) AND name = $3`)
+	mockdb.ExpectValueAt(t, 2, "start")
+
+	cursor, err = orm.Iter[Event](mockdb, query.Where("name = ?", "stop").OrderBy("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor.Close()
+	mockdb.ExpectSQL(t, `SELECT "id", "name" FROM "event" WHERE name = $1 ORDER BY id`)
+
+	if _, err := orm.ListTracked[Event](mockdb, "WHERE id IN ($1)", orm.In([]int64{3, 4})); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "name" FROM "event" WHERE id IN ($1, $2)`)
+
+	if _, err := orm.Iter[Event](mockdb, 42); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for a sql argument which is not a string or Clause, got %v", err)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	type Job struct {
+		ID     int64
+		Status string
+	}
+
+	batches := [][][]driver.Value{
+		{{int64(1), "queued"}, {int64(2), "queued"}},
+		{{int64(3), "queued"}},
+	}
+
+	mockdb := &mockDB{Columns: []string{"id", "status"}, Rows: batches[0]}
+	db := orm.New(mockdb)
+
+	var (
+		seen []int64
+		jobs []Job
+	)
+
+	err := orm.Chunk(db.Conn(), &jobs, 2, "status = $1", []any{"queued"}, func(_ orm.QuerierExecuterContext, batch []Job) error {
+		for _, j := range batch {
+			seen = append(seen, j.ID)
+		}
+
+		mockdb.Rows = batches[len(seen)/2]
+		return nil
+	})
+
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	mockdb.ExpectSQL(t, "INSERT INTO user (email, password) VALUES ($1, $2), ($3, $4), ($5, $6)")
-	mockdb.ExpectValueAt(t, 0, "user1@example.com")
-	mockdb.ExpectValueAt(t, 1, "password")
-	mockdb.ExpectValueAt(t, 2, "user2@example.com")
-	mockdb.ExpectValueAt(t, 3, "password")
+	if fmt.Sprint(seen) != "[1 2 3]" {
+		t.Fatalf("expected every row to be visited once, got %v", seen)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "status" FROM "job" WHERE (status = $1) AND "id" > $2 ORDER BY "id" LIMIT 2`)
+	mockdb.ExpectValueAt(t, 0, "queued")
+	mockdb.ExpectValueAt(t, 1, int64(2))
+
+	seen = nil
+	mockdb.Rows = batches[0]
+
+	err = orm.Chunk(db.Conn(), &jobs, 2, "status = :status", []any{map[string]any{"status": "queued"}}, func(_ orm.QuerierExecuterContext, batch []Job) error {
+		for _, j := range batch {
+			seen = append(seen, j.ID)
+		}
+
+		mockdb.Rows = batches[len(seen)/2]
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "status" FROM "job" WHERE (status = $1) AND "id" > $2 ORDER BY "id" LIMIT 2`)
+	if fmt.Sprint(mockdb.Values) != "[queued 2]" {
+		t.Fatalf("expected named parameters to be bound in every batch, got %v", mockdb.Values)
+	}
+
+	mockdb.Rows = batches[0]
+	mockdb.Statements = nil
+
+	errBatch := errors.New("batch")
+	err = orm.ChunkTx(db.Conn(), &jobs, 2, "", nil, func(tx orm.QuerierExecuterContext, batch []Job) error {
+		if batch[0].ID == 3 {
+			return errBatch
+		}
+
+		mockdb.Rows = batches[1]
+		_, err := tx.ExecContext(context.Background(), "UPDATE job SET status = 'done' WHERE id <= 2")
+		return err
+	})
+
+	if !errors.Is(err, errBatch) {
+		t.Fatalf("expected errBatch, got %v", err)
+	}
+
+	expected := []string{
+		"BEGIN",
+		`SELECT "id", "status" FROM "job" ORDER BY "id" LIMIT 2`,
+		"UPDATE job SET status = 'done' WHERE id <= 2",
+		"COMMIT",
+		"BEGIN",
+		`SELECT "id", "status" FROM "job" WHERE "id" > $1 ORDER BY "id" LIMIT 2`,
+		"ROLLBACK",
+	}
+
+	if strings.Join(mockdb.Statements, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected statements %q", mockdb.Statements)
+	}
 }
 
-func TestDbWrapper(t *testing.T) {
-	db := orm.New(&mockDB{})
+func TestClause(t *testing.T) {
+	type Task struct {
+		ID       int64
+		Title    string
+		Priority int
+	}
 
-	type Users struct {
+	mockdb := &mockDB{Columns: []string{"id", "title", "priority"}}
+	q := query.Where("priority > ?", 2).In("id", []int64{1, 2}).OrderBy("priority DESC").Limit(5)
+
+	var tasks []Task
+	if err := orm.List(mockdb, &tasks, q); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `SELECT "id", "title", "priority" FROM "task" WHERE priority > $1 AND id IN ($2, $3) ORDER BY priority DESC LIMIT 5`)
+	mockdb.ExpectValueAt(t, 2, int64(2))
+
+	if err := orm.Update(mockdb, &Task{Title: "done"}, query.Where("id = ?", 7)); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "task" SET "title" = $2, "priority" = $3 WHERE id = $1`)
+	mockdb.ExpectValueAt(t, 0, 7)
+
+	if err := orm.New(mockdb, orm.WithDialect(orm.MySQL)).Remove(&Task{}, query.New().IsNull("title")); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "DELETE FROM `task` WHERE title IS NULL")
+
+	if err := orm.Remove(mockdb, &Task{}, query.New().IsNull("title").Limit(1)); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for a limited remove, got %v", err)
+	}
+
+	if err := orm.Update(mockdb, &Task{}, query.Where("id = ?", 7).OrderBy("id")); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for an ordered update, got %v", err)
+	}
+
+	if err := orm.Get(mockdb, &Task{}, q, 1); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for arguments given along with a clause, got %v", err)
+	}
+}
+
+func TestNamedParams(t *testing.T) {
+	type Booking struct {
 		ID    int64
-		Email string
+		Guest string
+		Room  int
 	}
 
-	var users []Users
+	mockdb := &mockDB{Columns: []string{"id", "guest", "room"}}
+	params := map[string]any{"guest": "ann", "room": 4}
 
-	db.Add(&Users{
-		Email: "johndoe@gmail.com",
-	})
+	var bookings []Booking
+	sql := "WHERE guest = :guest -- not :room\nAND note <> ':room' AND created::date > @since /* :room */ OR host = :guest"
+	if err := orm.List(mockdb, &bookings, sql, map[string]any{"guest": "ann", "since": "2024-01-01"}); err != nil {
+		t.Fatal(err)
+	}
 
-	// don't know if we can actually test this
-	if err := db.ListAll(&users); err != nil {
+	mockdb.ExpectSQL(t, "SELECT \"id\", \"guest\", \"room\" FROM \"booking\" WHERE guest = $1 -- not :room\nAND note <> ':room' AND created::date > $2 /* :room */ OR host = $1")
+	if fmt.Sprint(mockdb.Values) != "[ann 2024-01-01]" {
+		t.Fatalf("expected each name to be bound once, got %v", mockdb.Values)
+	}
+
+	mockdb.Rows = [][]driver.Value{{int64(1), "bob", int64(3)}}
+	db := orm.New(mockdb, orm.WithDialect(orm.MySQL))
+	if err := db.Get(&Booking{}, "WHERE guest = :guest OR host = :guest", struct{ Guest string }{"bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "SELECT `id`, `guest`, `room` FROM `booking` WHERE guest = ? OR host = ?")
+	if fmt.Sprint(mockdb.Values) != "[bob bob]" {
+		t.Fatalf("expected positional placeholders to bind every use, got %v", mockdb.Values)
+	}
+
+	if err := orm.Update(mockdb, &Booking{Guest: "cid", Room: 2}, "WHERE room = :room", params); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "booking" SET "guest" = $2, "room" = $3 WHERE room = $1`)
+	mockdb.ExpectValueAt(t, 0, 4)
+
+	if _, err := orm.Count(mockdb, &Booking{}, "WHERE guest = :name", params); !errors.Is(err, orm.ErrMissingParam) {
+		t.Fatalf("expected ErrMissingParam, got %v", err)
+	}
+
+	if err := orm.Remove(mockdb, &Booking{}, "WHERE meta = $1::jsonb", params); err != nil {
 		t.Fatal(err)
 	}
+
+	mockdb.ExpectSQL(t, `DELETE FROM "booking" WHERE meta = $1::jsonb`)
+	if len(mockdb.Values) != 1 {
+		t.Fatalf("expected a map without named parameters to be bound as is, got %v", mockdb.Values)
+	}
+
+	if err := orm.Remove(mockdb, &Booking{}, "WHERE meta = $1 AND room = $2", params, 4); err != nil {
+		t.Fatalf("expected a map among positional arguments to be bound as is, got %v", err)
+	}
+
+	if err := orm.Remove(mockdb, &Booking{}, "WHERE guest = :guest AND room = $2", params, 4); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for a map mixed with other arguments, got %v", err)
+	}
 }
 
-func TestListAny(t *testing.T) {
-	db := mockDB{}
+func TestIn(t *testing.T) {
+	type Parcel struct {
+		ID     int64
+		Status string
+	}
 
-	type A struct {
-		ID       int64
-		Username string
-		Password string
+	mockdb := &mockDB{Columns: []string{"id", "status"}}
+
+	var parcels []Parcel
+	if err := orm.List(mockdb, &parcels, "WHERE id IN ($1) AND status = $2 AND note <> '$1'", orm.In([]int64{1, 2, 3}), "sent"); err != nil {
+		t.Fatal(err)
 	}
 
-	var a A
+	mockdb.ExpectSQL(t, `SELECT "id", "status" FROM "parcel" WHERE id IN ($1, $2, $3) AND status = $4 AND note <> '$1'`)
+	if fmt.Sprint(mockdb.Values) != "[1 2 3 sent]" {
+		t.Fatalf("expected the slice to be flattened into the arguments, got %v", mockdb.Values)
+	}
 
-	a.Username = "foo"
-	a.Password = "bar"
+	rowdb := &mockDB{Columns: []string{"id", "status"}, Rows: [][]driver.Value{{int64(2), "sent"}}}
 
-	var as []A
+	var parcel Parcel
+	if err := orm.QueryRow(rowdb, &parcel, "SELECT id, status FROM parcel WHERE id IN (:ids) AND status = :status", map[string]any{"ids": orm.In([]int64{1, 2}), "status": "sent"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rowdb.ExpectSQL(t, "SELECT id, status FROM parcel WHERE id IN ($1, $2) AND status = $3")
+	if fmt.Sprint(rowdb.Values) != "[1 2 sent]" || parcel.ID != 2 {
+		t.Fatalf("expected the named slice to be expanded, got %v scanning %+v", rowdb.Values, parcel)
+	}
+
+	if err := orm.Update(mockdb, &Parcel{Status: "lost"}, "WHERE id IN (:ids)", map[string]any{"ids": orm.In([]int64{4, 5})}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, `UPDATE "parcel" SET "status" = $3 WHERE id IN ($1, $2)`)
 
-	orm.List(&db, as, "WHERE username = $1", a.Username)
-	db.ExpectSQL(t, "SELECT id, username, password FROM a WHERE username = $1")
+	db := orm.New(mockdb, orm.WithDialect(orm.MySQL))
+	if err := db.Remove(&Parcel{}, "WHERE id IN (?) OR status = ?", orm.In([]int64{}), "void"); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectSQL(t, "DELETE FROM `parcel` WHERE id IN (SELECT NULL FROM (SELECT 1) AS orm_empty WHERE 1 = 0) OR status = ?")
+	if fmt.Sprint(mockdb.Values) != "[void]" {
+		t.Fatalf("expected an empty slice to bind no arguments, got %v", mockdb.Values)
+	}
+
+	if _, err := orm.Count(mockdb, &Parcel{}, "WHERE id IN ($2)", orm.In([]int64{1})); !errors.Is(err, orm.ErrInvalidType) {
+		t.Fatalf("expected ErrInvalidType for a placeholder without argument, got %v", err)
+	}
 }
 
-func TestUpdate(t *testing.T) {
-	db := mockDB{}
-	type A struct {
-		ID       int64
-		Username string
-		Password string
+func TestScanByColumn(t *testing.T) {
+	type Rated struct {
+		Stars int
 	}
-	var a A
 
-	a.Username = "foo"
-	a.Password = "bar"
-	orm.Update(&db, &a, "WHERE username = $1", a.Username)
-	db.ExpectSQL(t, "UPDATE a SET username = $2, password = $3 WHERE username = $1")
-	db.ExpectValueAt(t, 0, a.Username)
-	db.ExpectValueAt(t, 1, a.Username)
-	db.ExpectValueAt(t, 2, a.Password)
+	type Review struct {
+		ID int64
+		Rated
+		Body   string
+		Author string
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"body", "stars", "score", "id"},
+		Rows:    [][]driver.Value{{"great", int64(5), 0.9, int64(1)}, {"meh", int64(2), 0.4, int64(2)}},
+	}
+
+	var reviews []Review
+	if err := orm.Query(mockdb, &reviews, "SELECT body, stars, score, id FROM review"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reviews) != 2 || reviews[0].ID != 1 || reviews[0].Stars != 5 || reviews[1].Body != "meh" {
+		t.Fatalf("expected columns to be scanned by name, got %+v", reviews)
+	}
+
+	review := Review{Author: "ann"}
+	if err := orm.QueryRow(mockdb, &review, "SELECT body, stars, score, id FROM review"); err != nil {
+		t.Fatal(err)
+	}
+
+	if review.Body != "great" || review.Author != "ann" {
+		t.Fatalf("expected unselected fields to be left untouched, got %+v", review)
+	}
+
+	rows, err := mockdb.Query("SELECT body, stars, score, id FROM review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collected, err := orm.CollectRows[Review](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if collected[1].Stars != 2 || collected[1].ID != 2 {
+		t.Fatalf("expected collected rows to be scanned by name, got %+v", collected)
+	}
+
+	strict := orm.New(mockdb, orm.WithStrictColumns(true))
+	if err := strict.QueryRow(&review, "SELECT body, stars, score, id FROM review"); !errors.Is(err, orm.ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn for score, got %v", err)
+	}
+}
+
+func TestNullZero(t *testing.T) {
+	type Lead struct {
+		ID      int64
+		Company string `db:"company,nullzero"`
+		Score   int    `db:"score,nullzero"`
+	}
+
+	mockdb := &mockDB{
+		Columns: []string{"id", "company", "score"},
+		Rows:    [][]driver.Value{{int64(1), nil, nil}},
+	}
+
+	lead := Lead{Company: "acme", Score: 3}
+	if err := orm.GetByID(mockdb, &lead); err != nil {
+		t.Fatal(err)
+	}
+
+	if lead.Company != "" || lead.Score != 0 {
+		t.Fatalf("expected NULL to be scanned as zero values, got %+v", lead)
+	}
+
+	mockdb.Columns = []string{"score", "id"}
+	mockdb.Rows = [][]driver.Value{{nil, int64(2)}}
+
+	var leads []Lead
+	if err := orm.Query(mockdb, &leads, "SELECT score, id FROM lead"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(leads) != 1 || leads[0].ID != 2 {
+		t.Fatalf("expected NULL to be scanned by column name, got %+v", leads)
+	}
+
+	if err := orm.UpdateByID(mockdb, &Lead{ID: 2, Score: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockdb.ExpectValueAt(t, 0, nil)
+	mockdb.ExpectValueAt(t, 1, 5)
 }
 
 func TestFieldsFindByColumn(t *testing.T) {
@@ -133,7 +1583,7 @@ func TestOneNoSQL(t *testing.T) {
 	db := &mockDB{}
 	var foo TempTable
 	orm.Get(db, &foo, "")
-	db.ExpectSQL(t, "SELECT v FROM temp_table")
+	db.ExpectSQL(t, `SELECT "v" FROM "temp_table"`)
 }
 
 func TestOneSQL(t *testing.T) {
@@ -141,10 +1591,32 @@ func TestOneSQL(t *testing.T) {
 	db := &mockDB{}
 	var foo TempTable
 	orm.Get(db, &foo, "WHERE v = $1", 1)
-	db.ExpectSQL(t, "SELECT v FROM temp_table WHERE v = $1")
+	db.ExpectSQL(t, `SELECT "v" FROM "temp_table" WHERE v = $1`)
 	db.ExpectValueAt(t, 0, 1)
 }
 
+func TestGetContext(t *testing.T) {
+	type TempTable struct{ V string }
+	mockdb := &mockDB{}
+	db := orm.New(mockdb)
+	var foo TempTable
+	db.GetContext(context.Background(), &foo, "WHERE v = $1", 1)
+	mockdb.ExpectSQL(t, `SELECT "v" FROM "temp_table" WHERE v = $1`)
+	mockdb.ExpectValueAt(t, 0, 1)
+}
+
+func TestGetContextCanceled(t *testing.T) {
+	type TempTable struct{ V string }
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var foo TempTable
+	err := orm.GetContext(ctx, (&mockDB{}).fake(), &foo, "")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
 func TestExec(t *testing.T) {
 	db := &mockDB{}
 	sql := "create table test_table (id serial primary key)"
@@ -153,19 +1625,31 @@ func TestExec(t *testing.T) {
 	db.ExpectSQL(t, sql)
 }
 
-type mockResult struct{}
+type mockResult struct{ noRows bool }
 
 func (mockResult) LastInsertId() (int64, error) {
 	return 1, nil
 }
 
-func (mockResult) RowsAffected() (int64, error) {
+func (r mockResult) RowsAffected() (int64, error) {
+	if r.noRows {
+		return 0, nil
+	}
+
 	return 1, nil
 }
 
+// mockDB records the last statement executed.
+// Queries are answered with Columns and Rows through a fake driver so that real *sql.Rows are returned.
+// Every statement reaching the fake driver, including those executed within a transaction, is appended to Statements.
 type mockDB struct {
-	SQL    string
-	Values []any
+	SQL        string
+	Values     []any
+	Columns    []string
+	Rows       [][]driver.Value
+	Statements []string
+	NoRows     bool // report that executed statements affected no rows
+	conn       *sql.DB
 }
 
 func (db *mockDB) ExpectSQL(t *testing.T, sql string) {
@@ -174,10 +1658,6 @@ func (db *mockDB) ExpectSQL(t *testing.T, sql string) {
 	}
 }
 
-func (db *mockDB) Begin() (*sql.Tx, error) {
-	return nil, nil
-}
-
 func (db *mockDB) ExpectValueAt(t *testing.T, index int, value interface{}) {
 	if db.Values[index] != value {
 		t.Fatalf("expected value at index %d to be %v\ngot %v",
@@ -185,20 +1665,102 @@ func (db *mockDB) ExpectValueAt(t *testing.T, index int, value interface{}) {
 	}
 }
 
+func (db *mockDB) fake() *sql.DB {
+	if db.conn == nil {
+		db.conn = sql.OpenDB(&fakeConnector{db})
+	}
+
+	return db.conn
+}
+
+func (db *mockDB) Begin() (*sql.Tx, error) {
+	return db.fake().Begin()
+}
+
 func (db *mockDB) Exec(s string, args ...any) (sql.Result, error) {
 	db.SQL = s
 	db.Values = args
-	return mockResult{}, nil
+	return mockResult{noRows: db.NoRows}, nil
 }
 
 func (db *mockDB) Query(s string, args ...any) (*sql.Rows, error) {
 	db.SQL = s
 	db.Values = args
-	return nil, errors.New("test implementation")
+	return db.fake().Query(s)
 }
 
 func (db *mockDB) QueryRow(s string, args ...any) *sql.Row {
 	db.SQL = s
 	db.Values = args
+	return db.fake().QueryRow(s)
+}
+
+// fakeConnector opens connections which answer queries from the mockDB
+type fakeConnector struct{ mock *mockDB }
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{c.mock}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver must be opened with a connector")
+}
+
+type fakeConn struct{ mock *mockDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake driver does not support prepared statements")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.mock.Statements = append(c.mock.Statements, "BEGIN")
+	return fakeTx{c.mock}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.mock.Statements = append(c.mock.Statements, query)
+	return mockResult{}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.mock.Statements = append(c.mock.Statements, query)
+	return &fakeRows{columns: c.mock.Columns, rows: c.mock.Rows}, nil
+}
+
+type fakeTx struct{ mock *mockDB }
+
+func (tx fakeTx) Commit() error {
+	tx.mock.Statements = append(tx.mock.Statements, "COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.mock.Statements = append(tx.mock.Statements, "ROLLBACK")
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.pos])
+	r.pos++
 	return nil
 }
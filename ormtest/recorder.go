@@ -0,0 +1,57 @@
+// Package ormtest provides utilities for testing code which uses orm
+package ormtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cristosal/orm"
+)
+
+// Span is a finished span kept by a Recorder
+type Span struct {
+	orm.SpanInfo
+	Err error // Error the statement ended with
+}
+
+// Recorder is an in-memory orm.Tracer which keeps every finished span.
+// It is safe for concurrent use
+type Recorder struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start implements orm.Tracer
+func (r *Recorder) Start(ctx context.Context, info orm.SpanInfo) (context.Context, orm.Span) {
+	return ctx, &recordedSpan{recorder: r, info: info}
+}
+
+// Spans returns the finished spans in the order they ended
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span(nil), r.spans...)
+}
+
+// Reset discards all recorded spans
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+type recordedSpan struct {
+	recorder *Recorder
+	info     orm.SpanInfo
+}
+
+func (s *recordedSpan) End(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.spans = append(s.recorder.spans, Span{SpanInfo: s.info, Err: err})
+}
@@ -0,0 +1,21 @@
+module github.com/cristosal/orm/otelorm
+
+go 1.21.4
+
+require (
+	github.com/cristosal/orm v0.0.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
+
+// orm has no tagged release yet, so otelorm is built against the orm module of this repository
+replace github.com/cristosal/orm => ../
@@ -0,0 +1,64 @@
+// Package otelorm traces statements issued through an orm.ORM with OpenTelemetry.
+//
+//	db := orm.New(sqlDB, orm.WithTracer(otelorm.NewTracer(otel.GetTracerProvider())))
+package otelorm
+
+import (
+	"context"
+
+	"github.com/cristosal/orm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer obtained from the provider
+const InstrumentationName = "github.com/cristosal/orm/otelorm"
+
+// Tracer is an orm.Tracer which starts OpenTelemetry client spans
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a Tracer which starts spans from a tracer of the provider
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+// Start implements orm.Tracer
+func (t *Tracer) Start(ctx context.Context, info orm.SpanInfo) (context.Context, orm.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", system(info.System)),
+		attribute.String("db.statement", info.Statement),
+		attribute.String("db.operation", info.Operation),
+	}
+
+	if info.Table != "" {
+		attrs = append(attrs, attribute.String("db.sql.table", info.Table))
+	}
+
+	ctx, span := t.tracer.Start(ctx, info.Name(), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, &otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+// system maps dialect names to the well known db.system values
+func system(name string) string {
+	if name == "postgres" {
+		return "postgresql"
+	}
+
+	return name
+}
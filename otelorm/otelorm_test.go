@@ -0,0 +1,51 @@
+package otelorm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cristosal/orm"
+	"github.com/cristosal/orm/otelorm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	var (
+		exporter = tracetest.NewInMemoryExporter()
+		provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		tracer   = otelorm.NewTracer(provider)
+		failure  = errors.New("failure")
+	)
+
+	_, span := tracer.Start(context.Background(), orm.SpanInfo{
+		System:    "postgres",
+		Statement: "SELECT id FROM account WHERE id = $1",
+		Operation: "SELECT",
+		Table:     "account",
+	})
+
+	span.End(failure)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	s := spans[0]
+	if s.Name != "SELECT account" || s.Status.Code != codes.Error {
+		t.Fatalf("unexpected span %s with status %v", s.Name, s.Status)
+	}
+
+	attrs := attribute.NewSet(s.Attributes...)
+	if v, _ := attrs.Value("db.system"); v.AsString() != "postgresql" {
+		t.Fatalf("expected db.system to be postgresql, got %s", v.AsString())
+	}
+
+	if v, _ := attrs.Value("db.sql.table"); v.AsString() != "account" {
+		t.Fatalf("expected db.sql.table to be account, got %s", v.AsString())
+	}
+}
@@ -1,7 +1,7 @@
 package orm
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"strings"
 
@@ -43,6 +43,11 @@ func (opts *PaginationOptions) sqlQueryParam() string {
 
 // Paginate returns paginated data for T
 func Paginate[T any](db DB, v *[]T, opts *PaginationOptions) (*PaginationResults, error) {
+	return PaginateContext(context.Background(), querierContext(db), v, opts)
+}
+
+// PaginateContext returns paginated data for T
+func PaginateContext[T any](ctx context.Context, db QuerierContext, v *[]T, opts *PaginationOptions) (*PaginationResults, error) {
 	if opts == nil {
 		opts = &PaginationOptions{
 			Page:     1,
@@ -51,39 +56,33 @@ func Paginate[T any](db DB, v *[]T, opts *PaginationOptions) (*PaginationResults
 	}
 
 	var t T
-	sch, _, err := schema.GetMapping(&t)
-	if err != nil {
+	if _, _, err := schema.GetMapping(&t); err != nil {
 		return nil, err
 	}
 
-	sqlstr := ""
+	var (
+		sqlstr string
+		args   []any
+		d      = dialectOf(db)
+	)
+
 	if opts.queryable() {
 		var parts []string
-		for _, col := range opts.QueryColumns {
-			parts = append(parts, fmt.Sprintf("%s LIKE $1", col))
+		for i, col := range opts.QueryColumns {
+			// positional placeholders require the parameter to be bound for each column
+			if !schema.Numbered(d) || i == 0 {
+				args = append(args, opts.sqlQueryParam())
+			}
+
+			parts = append(parts, fmt.Sprintf("%s LIKE %s", col, d.Placeholder(len(args))))
 		}
 
 		likeClause := strings.Join(parts, " OR ")
 		sqlstr = fmt.Sprintf("WHERE %s", likeClause)
 	}
 
-	CountWhere(sch.Table, sqlstr)
-
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", sch.Table, sqlstr)
-	var row *sql.Row
-
-	if opts.queryable() {
-		row = db.QueryRow(countQuery, opts.sqlQueryParam())
-	} else {
-		row = db.QueryRow(countQuery)
-	}
-
-	if row == nil {
-		return nil, sql.ErrNoRows
-	}
-
-	var count int64
-	if err := row.Scan(&count); err != nil {
+	count, err := CountContext(ctx, db, &t, sqlstr, args...)
+	if err != nil {
 		return nil, err
 	}
 
@@ -92,15 +91,9 @@ func Paginate[T any](db DB, v *[]T, opts *PaginationOptions) (*PaginationResults
 	}
 
 	offset := opts.Page * opts.PageSize
-	sqlstr = fmt.Sprintf("%s LIMIT %d OFFSET %d", sqlstr, opts.PageSize, offset)
+	sqlstr = fmt.Sprintf("%s %s", sqlstr, d.LimitOffset(opts.PageSize, offset))
 
-	if opts.queryable() {
-		err = List(db, v, sqlstr, opts.sqlQueryParam())
-	} else {
-		err = List(db, v, sqlstr)
-	}
-
-	if err != nil {
+	if err := ListContext(ctx, db, v, sqlstr, args...); err != nil {
 		return nil, err
 	}
 
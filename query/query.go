@@ -0,0 +1,276 @@
+// Package query builds the sql clauses which follow the generated part of a statement, such as WHERE and ORDER BY.
+// Conditions are written with ? markers, which are numbered for the dialect the clause is built for.
+// A Builder is accepted by orm.Get, orm.List, orm.Count, orm.Update and orm.Remove in place of their sql string.
+// Update and Remove only accept builders of WHERE conditions, see WhereOnly.
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// Builder composes a clause from its parts. The methods modify the builder and return it for chaining,
+// so optional parts can be added conditionally. Parts are rendered in sql order regardless of the order they are added in.
+type Builder struct {
+	joins   []expr
+	where   []cond
+	groupBy []string
+	having  []cond
+	orderBy []string
+	limit   int
+	offset  int
+}
+
+// expr is a fragment of sql with ? markers and the arguments they bind
+type expr struct {
+	sql  string
+	args []any
+}
+
+// cond is an expression joined to the conditions before it with op. Grouped conditions are always parenthesized
+type cond struct {
+	op      string
+	grouped bool
+	expr
+}
+
+// New returns an empty builder
+func New() *Builder {
+	return &Builder{}
+}
+
+// Where returns a builder with the condition
+func Where(sql string, args ...any) *Builder {
+	return New().Where(sql, args...)
+}
+
+// Where adds a condition which must hold along with the conditions before it. It is the same as And
+func (b *Builder) Where(sql string, args ...any) *Builder {
+	return b.And(sql, args...)
+}
+
+// And adds a condition which must hold along with the conditions before it
+func (b *Builder) And(sql string, args ...any) *Builder {
+	b.where = append(b.where, cond{op: "AND", expr: expr{sql, args}})
+	return b
+}
+
+// Or adds a condition which may hold instead of the conditions before it
+func (b *Builder) Or(sql string, args ...any) *Builder {
+	b.where = append(b.where, cond{op: "OR", expr: expr{sql, args}})
+	return b
+}
+
+// AndGroup adds the conditions of g in parentheses, which must hold along with the conditions before them
+func (b *Builder) AndGroup(g *Builder) *Builder {
+	return b.group("AND", g)
+}
+
+// OrGroup adds the conditions of g in parentheses, which may hold instead of the conditions before them
+func (b *Builder) OrGroup(g *Builder) *Builder {
+	return b.group("OR", g)
+}
+
+func (b *Builder) group(op string, g *Builder) *Builder {
+	if len(g.where) == 0 {
+		return b
+	}
+
+	sql, args := conditions(g.where)
+	b.where = append(b.where, cond{op: op, grouped: true, expr: expr{sql, args}})
+	return b
+}
+
+// In adds a condition matching rows where col equals one of values. A single slice argument is expanded into its elements.
+// No rows are matched when there are no values
+func (b *Builder) In(col string, values ...any) *Builder {
+	values = expand(values)
+	if len(values) == 0 {
+		return b.And("1 = 0")
+	}
+
+	markers := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return b.And(fmt.Sprintf("%s IN (%s)", col, markers), values...)
+}
+
+// Between adds a condition matching rows where col is within the inclusive range of low and high
+func (b *Builder) Between(col string, low, high any) *Builder {
+	return b.And(col+" BETWEEN ? AND ?", low, high)
+}
+
+// IsNull adds a condition matching rows where col is null
+func (b *Builder) IsNull(col string) *Builder {
+	return b.And(col + " IS NULL")
+}
+
+// IsNotNull adds a condition matching rows where col is not null
+func (b *Builder) IsNotNull(col string) *Builder {
+	return b.And(col + " IS NOT NULL")
+}
+
+// Join adds a join clause, such as "JOIN address ON address.user_id = user.id"
+func (b *Builder) Join(sql string, args ...any) *Builder {
+	b.joins = append(b.joins, expr{sql, args})
+	return b
+}
+
+// GroupBy adds columns to group the rows by
+func (b *Builder) GroupBy(cols ...string) *Builder {
+	b.groupBy = append(b.groupBy, cols...)
+	return b
+}
+
+// Having adds a condition on the groups which must hold along with the conditions before it
+func (b *Builder) Having(sql string, args ...any) *Builder {
+	b.having = append(b.having, cond{op: "AND", expr: expr{sql, args}})
+	return b
+}
+
+// OrderBy adds columns to sort the rows by, each optionally followed by ASC or DESC
+func (b *Builder) OrderBy(cols ...string) *Builder {
+	b.orderBy = append(b.orderBy, cols...)
+	return b
+}
+
+// Limit sets the maximum number of rows. A limit less than 1 means no limit
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = n
+	return b
+}
+
+// Offset sets the number of rows skipped
+func (b *Builder) Offset(n int) *Builder {
+	b.offset = n
+	return b
+}
+
+// WhereOnly is true when the builder has no parts other than its WHERE conditions, so that it can follow an UPDATE or DELETE
+func (b *Builder) WhereOnly() bool {
+	return len(b.joins) == 0 && len(b.groupBy) == 0 && len(b.having) == 0 && len(b.orderBy) == 0 && b.limit < 1 && b.offset < 1
+}
+
+// Build renders the clause for the dialect, returning the sql and its arguments in the order they are bound
+func (b *Builder) Build(d schema.Dialect) (string, []any) {
+	var (
+		parts []string
+		args  []any
+	)
+
+	for _, j := range b.joins {
+		parts = append(parts, j.sql)
+		args = append(args, j.args...)
+	}
+
+	if len(b.where) > 0 {
+		sql, wargs := conditions(b.where)
+		parts = append(parts, "WHERE "+sql)
+		args = append(args, wargs...)
+	}
+
+	if len(b.groupBy) > 0 {
+		parts = append(parts, "GROUP BY "+strings.Join(b.groupBy, ", "))
+	}
+
+	if len(b.having) > 0 {
+		sql, hargs := conditions(b.having)
+		parts = append(parts, "HAVING "+sql)
+		args = append(args, hargs...)
+	}
+
+	if len(b.orderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(b.orderBy, ", "))
+	}
+
+	if lo := d.LimitOffset(b.limit, b.offset); lo != "" {
+		parts = append(parts, lo)
+	}
+
+	return number(d, strings.Join(parts, " ")), args
+}
+
+// conditions joins the conditions with their operators, parenthesizing those which contain an OR of their own.
+// Conditions are combined from left to right, so the conditions before an AND are parenthesized when they are joined by an OR
+func conditions(conds []cond) (string, []any) {
+	var (
+		sql  string
+		args []any
+		or   bool
+	)
+
+	for i, c := range conds {
+		part := c.sql
+		if c.grouped || len(conds) > 1 && strings.Contains(strings.ToUpper(c.sql), " OR ") {
+			part = "(" + part + ")"
+		}
+
+		switch {
+		case i == 0:
+			sql = part
+		case c.op == "AND" && or:
+			sql = "(" + sql + ") AND " + part
+			or = false
+		default:
+			sql += " " + c.op + " " + part
+			or = or || c.op == "OR"
+		}
+
+		args = append(args, c.args...)
+	}
+
+	return sql, args
+}
+
+// number replaces the ? markers of sql with the placeholders of the dialect.
+// Markers within quoted strings are left as is, and ?? is written as a literal ?
+func number(d schema.Dialect, sql string) string {
+	var (
+		sb    strings.Builder
+		n     int
+		quote rune
+	)
+
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '?':
+			i++
+		case r == '?':
+			n++
+			sb.WriteString(d.Placeholder(n))
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// expand returns the elements of values when it holds a single slice, other than []byte
+func expand(values []any) []any {
+	if len(values) != 1 {
+		return values
+	}
+
+	v := reflect.ValueOf(values[0])
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return values
+	}
+
+	elems := make([]any, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+
+	return elems
+}
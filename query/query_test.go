@@ -0,0 +1,112 @@
+package query_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cristosal/orm/query"
+	"github.com/cristosal/orm/schema"
+)
+
+func TestBuild(t *testing.T) {
+	tt := []struct {
+		name     string
+		builder  *query.Builder
+		dialect  schema.Dialect
+		expected string
+		args     string
+	}{
+		{
+			name:     "empty",
+			builder:  query.New(),
+			dialect:  schema.Postgres,
+			expected: "",
+			args:     "[]",
+		},
+		{
+			name:     "conditions",
+			builder:  query.Where("age > ?", 18).And("name = ? OR email = ?", "a", "b").IsNull("deleted_at"),
+			dialect:  schema.Postgres,
+			expected: "WHERE age > $1 AND (name = $2 OR email = $3) AND deleted_at IS NULL",
+			args:     "[18 a b]",
+		},
+		{
+			name:     "and after or",
+			builder:  query.Where("a = ?", 1).Or("b = ?", 2).And("c = ?", 3).Or("d = ?", 4).And("e = ?", 5),
+			dialect:  schema.Postgres,
+			expected: "WHERE ((a = $1 OR b = $2) AND c = $3 OR d = $4) AND e = $5",
+			args:     "[1 2 3 4 5]",
+		},
+		{
+			name:     "or after and",
+			builder:  query.Where("a = ?", 1).And("b = ?", 2).Or("c = ?", 3),
+			dialect:  schema.Postgres,
+			expected: "WHERE a = $1 AND b = $2 OR c = $3",
+			args:     "[1 2 3]",
+		},
+		{
+			name:     "groups",
+			builder:  query.Where("active").OrGroup(query.Where("role = ?", "admin").Between("age", 18, 65)),
+			dialect:  schema.SQLite,
+			expected: "WHERE active OR (role = ?1 AND age BETWEEN ?2 AND ?3)",
+			args:     "[admin 18 65]",
+		},
+		{
+			name:     "in",
+			builder:  query.New().In("id", []int64{1, 2, 3}).In("status"),
+			dialect:  schema.MySQL,
+			expected: "WHERE id IN (?, ?, ?) AND 1 = 0",
+			args:     "[1 2 3]",
+		},
+		{
+			name: "clauses in sql order",
+			builder: query.New().
+				OrderBy("total DESC").
+				Limit(10).
+				Offset(20).
+				Having("SUM(total) > ?", 100).
+				GroupBy("customer_id").
+				Where("status = ?", "paid").
+				Join("JOIN customer ON customer.id = invoice.customer_id AND customer.region = ?", "eu"),
+			dialect:  schema.Postgres,
+			expected: "JOIN customer ON customer.id = invoice.customer_id AND customer.region = $1 WHERE status = $2 GROUP BY customer_id HAVING SUM(total) > $3 ORDER BY total DESC LIMIT 10 OFFSET 20",
+			args:     "[eu paid 100]",
+		},
+		{
+			name:     "quoted markers",
+			builder:  query.Where("note <> '?' AND tags ?? 'x' AND id = ?", 1),
+			dialect:  schema.Postgres,
+			expected: "WHERE note <> '?' AND tags ? 'x' AND id = $1",
+			args:     "[1]",
+		},
+	}
+
+	for _, tc := range tt {
+		sql, args := tc.builder.Build(tc.dialect)
+		if sql != tc.expected {
+			t.Fatalf("%s: expected:\n%s\n\ngot:\n%s", tc.name, tc.expected, sql)
+		}
+
+		if got := fmt.Sprint(args); got != tc.args {
+			t.Fatalf("%s: expected args %s got %s", tc.name, tc.args, got)
+		}
+	}
+}
+
+func TestWhereOnly(t *testing.T) {
+	if !query.Where("id = ?", 1).IsNotNull("name").WhereOnly() {
+		t.Fatal("expected conditions to be where only")
+	}
+
+	for _, b := range []*query.Builder{
+		query.Where("id = ?", 1).OrderBy("id"),
+		query.Where("id = ?", 1).Limit(1),
+		query.New().Offset(5),
+		query.New().Join("JOIN team ON team.id = user.team_id"),
+		query.New().GroupBy("team_id"),
+	} {
+		if b.WhereOnly() {
+			t.Fatalf("expected %+v not to be where only", b)
+		}
+	}
+}
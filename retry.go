@@ -0,0 +1,105 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy determines how RetryTx re-runs transactions which fail with a retryable error
+type RetryPolicy struct {
+	MaxAttempts int                // Number of times the transaction is run, including the first. Values below 1 run it once
+	Backoff     time.Duration      // Delay before the first retry, doubled for every retry after it
+	MaxBackoff  time.Duration      // Upper bound of the delay. Zero leaves it unbounded
+	Jitter      float64            // Fraction of the delay which is randomized, between 0 and 1
+	Retryable   func(error) bool   // Classifies errors worth retrying. Defaults to IsRetryable
+	Isolation   sql.IsolationLevel // Isolation level the transaction is started with
+	ReadOnly    bool               // Start read-only transactions
+}
+
+// DefaultRetryPolicy is used by RetryTx when no policy is given
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     10 * time.Millisecond,
+	MaxBackoff:  time.Second,
+	Jitter:      0.5,
+}
+
+// sqlStater is implemented by driver errors which report their SQLSTATE code, such as those of pgx and lib/pq
+type sqlStater interface {
+	SQLState() string
+}
+
+// IsRetryable reports whether err is a serialization failure (40001) or deadlock (40P01).
+// The code is read from errors implementing SQLState() string.
+func IsRetryable(err error) bool {
+	var se sqlStater
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	switch se.SQLState() {
+	case "40001", "40P01":
+		return true
+	default:
+		return false
+	}
+}
+
+// delay returns the time to wait before the given retry, starting at 1
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff << (retry - 1)
+	if d < 0 || p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+
+	return d
+}
+
+// RetryTx runs fn within a transaction, re-running it according to policy when it fails with a retryable error.
+// See RetryTxContext for details
+func (o *ORM) RetryTx(policy *RetryPolicy, fn func(*ORM) error) error {
+	return o.RetryTxContext(context.Background(), policy, fn)
+}
+
+// RetryTxContext runs fn within a transaction, re-running it according to policy when it fails with a retryable error.
+// The transaction is rolled back before every retry, so fn must not have side effects outside of it.
+// DefaultRetryPolicy is used when policy is nil.
+// When the ORM is itself bound to a transaction, fn runs once within a savepoint as a failure aborts the outer transaction.
+func (o *ORM) RetryTxContext(ctx context.Context, policy *RetryPolicy, fn func(*ORM) error) error {
+	if policy == nil {
+		policy = &DefaultRetryPolicy
+	}
+
+	if o.txDepth > 0 {
+		return o.TxContext(ctx, nil, fn)
+	}
+
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	opts := &sql.TxOptions{Isolation: policy.Isolation, ReadOnly: policy.ReadOnly}
+
+	for attempt := 1; ; attempt++ {
+		err := o.TxContext(ctx, opts, fn)
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// ErrUnknownColumn is returned when scanning by column name in strict mode, and a column of the result set has no field
+var ErrUnknownColumn = errors.New("unknown column")
+
+// StrictColumns makes Query, QueryRow and CollectRows fail with ErrUnknownColumn when a column of the result set has no field,
+// instead of discarding it. It applies when the db argument does not carry a mode of its own, see the WithStrictColumns option.
+var StrictColumns = false
+
+// ColumnRows are rows which report the columns of their result set, such as *sql.Rows
+type ColumnRows interface {
+	Rows
+	Columns() ([]string, error)
+}
+
+// WithStrictColumns sets whether columns without a field fail with ErrUnknownColumn when scanning by column name. Defaults to StrictColumns
+func WithStrictColumns(strict bool) Option {
+	return func(o *ORM) { o.strict = &strict }
+}
+
+// strictColumner is implemented by database handles which are bound to a strict mode
+type strictColumner interface {
+	StrictColumns() bool
+}
+
+// strictOf returns the strict mode bound to db, falling back to StrictColumns
+func strictOf(db any) bool {
+	if s, ok := db.(strictColumner); ok {
+		return s.StrictColumns()
+	}
+
+	return StrictColumns
+}
+
+// columnField is the field a column of a result set is scanned into
+type columnField struct {
+	field *schema.FieldMapping
+	index []int
+}
+
+// columnFields returns the field of each column.
+// The field of a column without one is nil, or ErrUnknownColumn is returned when strict.
+func columnFields(mapping *schema.StructMapping, cols []string, strict bool) ([]*columnField, error) {
+	fields := make([]*columnField, len(cols))
+	for i, col := range cols {
+		field, index, err := mapping.Fields.FindByColumn(col)
+		if err == nil {
+			fields[i] = &columnField{field, index}
+			continue
+		}
+
+		if strict {
+			return nil, fmt.Errorf("%w: %s has no field in %s", ErrUnknownColumn, col, mapping.Type)
+		}
+	}
+
+	return fields, nil
+}
+
+// collectFields returns the fields of T which the columns of rows are scanned into
+func collectFields[T any](rows ColumnRows) ([]*columnField, error) {
+	mapping, _, err := schema.GetMapping(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	return columnFields(mapping, cols, StrictColumns)
+}
+
+// scanColumns scans row into the given fields of v, discarding columns without one.
+// Fields without a column are left untouched.
+func scanColumns(row Row, v any, fields []*columnField) error {
+	var (
+		val     = reflect.ValueOf(v).Elem()
+		dest    = make([]any, len(fields))
+		discard any
+	)
+
+	for i, f := range fields {
+		if f == nil {
+			dest[i] = &discard
+			continue
+		}
+
+		d, err := schema.ScanDest(f.field, val.FieldByIndex(f.index))
+		if err != nil {
+			return err
+		}
+
+		dest[i] = d
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	return runHook(v, func(h AfterScanner) error { return h.AfterScan() })
+}
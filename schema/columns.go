@@ -17,22 +17,39 @@ func (c Columns) Len() int {
 	return len(c)
 }
 
+// Quoted returns the columns quoted as identifiers of the given dialect
+func (c Columns) Quoted(d Dialect) Columns {
+	quoted := make(Columns, len(c))
+	for i, col := range c {
+		quoted[i] = d.Quote(col)
+	}
+	return quoted
+}
+
 // ValueList returns a postgres parameter list in the format of $1, $2, ...
 // The start value determines when counting starts.
 func (c Columns) ValueList(start int) string {
-	var parts []string
-	for i := range c {
-		parts = append(parts, fmt.Sprintf("$%d", start+i))
-	}
-	return strings.Join(parts, ", ")
+	return ValueListFor(Postgres, len(c), start)
+}
+
+// ValueListFor returns a parameter list using the placeholders of the given dialect.
+// The start value determines when counting starts.
+func (c Columns) ValueListFor(d Dialect, start int) string {
+	return ValueListFor(d, len(c), start)
 }
 
 // ValueList returns a postgres parameter list in the format of $1, $2, ...
 // The start value determines when counting starts.
 func ValueList(length, start int) string {
+	return ValueListFor(Postgres, length, start)
+}
+
+// ValueListFor returns a parameter list of the given length using the placeholders of the given dialect.
+// The start value determines when counting starts.
+func ValueListFor(d Dialect, length, start int) string {
 	var parts []string
 	for i := 0; i < length; i++ {
-		parts = append(parts, fmt.Sprintf("$%d", start+i))
+		parts = append(parts, d.Placeholder(start+i))
 	}
 	return strings.Join(parts, ", ")
 }
@@ -50,9 +67,15 @@ func (c Columns) PrefixedList(prefix string) string {
 // AssignmentList returns an assignment list in the format of column1 = $1, column2 = $2, ...
 // The start argument determines the initial number for the parameters.
 func (c Columns) AssignmentList(start int) string {
+	return c.AssignmentListFor(Postgres, start)
+}
+
+// AssignmentListFor returns an assignment list using the placeholders of the given dialect.
+// The start argument determines the initial number for the parameters.
+func (c Columns) AssignmentListFor(d Dialect, start int) string {
 	var assignments []string
 	for i, col := range c {
-		assignments = append(assignments, fmt.Sprintf("%s = $%d", col, start+i))
+		assignments = append(assignments, fmt.Sprintf("%s = %s", col, d.Placeholder(start+i)))
 	}
 	return strings.Join(assignments, ", ")
 }
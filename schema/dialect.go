@@ -0,0 +1,188 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect describes the differences in sql syntax between databases
+type Dialect interface {
+	// Name of the dialect
+	Name() string
+
+	// Placeholder returns the bind parameter for the nth argument. Counting starts at 1
+	Placeholder(n int) string
+
+	// Quote quotes an identifier. Each part of a dotted identifier is quoted separately.
+	// Generated statements quote every table and column name, so that reserved words such as user can be used
+	Quote(ident string) string
+
+	// Returning is true when statements support a RETURNING clause
+	Returning() bool
+
+	// LastInsertID is true when generated ids are reported through sql.Result.LastInsertId
+	LastInsertID() bool
+
+	// MaxParams is the maximum number of bind parameters allowed in a single statement
+	MaxParams() int
+
+	// LimitOffset returns a clause limiting the result set. A limit less than 1 means no limit
+	LimitOffset(limit, offset int) string
+
+	// TypeName translates a column type used by TableDefinition into the dialect's equivalent
+	TypeName(typ string) string
+
+	// OnConflict returns the clause of an insert which updates the given columns when a row conflicts on target.
+	// Conflicting rows are left untouched when no columns are given. The columns are already quoted.
+	// An empty clause is returned when the dialect cannot leave conflicting rows untouched without a target
+	OnConflict(target, update Columns) string
+}
+
+var (
+	// Postgres dialect uses numbered $n placeholders and supports RETURNING clauses
+	Postgres Dialect = postgres{}
+
+	// SQLite dialect uses numbered ?n placeholders. RETURNING clauses require SQLite 3.35 or later
+	SQLite Dialect = sqlite{}
+
+	// MySQL dialect uses positional ? placeholders and reports generated ids through LastInsertId
+	MySQL Dialect = mysql{}
+)
+
+// Numbered is true when the placeholders of the dialect are numbered.
+// Arguments for dialects without numbered placeholders must be given in the order they appear in the statement.
+func Numbered(d Dialect) bool {
+	return d.Placeholder(1) != d.Placeholder(2)
+}
+
+type postgres struct{}
+
+func (postgres) Name() string                         { return "postgres" }
+func (postgres) Placeholder(n int) string             { return fmt.Sprintf("$%d", n) }
+func (postgres) Quote(ident string) string            { return quote(ident, '"') }
+func (postgres) Returning() bool                      { return true }
+func (postgres) LastInsertID() bool                   { return false }
+func (postgres) MaxParams() int                       { return 65535 }
+func (postgres) TypeName(typ string) string           { return typ }
+func (postgres) LimitOffset(limit, offset int) string { return limitOffset(limit, offset, "") }
+
+func (postgres) OnConflict(target, update Columns) string { return onConflict(target, update) }
+
+type sqlite struct{}
+
+func (sqlite) Name() string                         { return "sqlite" }
+func (sqlite) Placeholder(n int) string             { return fmt.Sprintf("?%d", n) }
+func (sqlite) Quote(ident string) string            { return quote(ident, '"') }
+func (sqlite) Returning() bool                      { return true }
+func (sqlite) LastInsertID() bool                   { return true }
+func (sqlite) MaxParams() int                       { return 32766 }
+func (sqlite) LimitOffset(limit, offset int) string { return limitOffset(limit, offset, "-1") }
+
+func (sqlite) OnConflict(target, update Columns) string { return onConflict(target, update) }
+
+func (sqlite) TypeName(typ string) string {
+	switch typ {
+	case "SERIAL":
+		return "INTEGER"
+	case "TIMESTAMPTZ":
+		return "TIMESTAMP"
+	case "JSONB":
+		return "JSON"
+	case "INTERVAL":
+		return "TEXT"
+	default:
+		return typ
+	}
+}
+
+type mysql struct{}
+
+func (mysql) Name() string              { return "mysql" }
+func (mysql) Placeholder(int) string    { return "?" }
+func (mysql) Quote(ident string) string { return quote(ident, '`') }
+func (mysql) Returning() bool           { return false }
+func (mysql) LastInsertID() bool        { return true }
+func (mysql) MaxParams() int            { return 65535 }
+func (mysql) LimitOffset(limit, offset int) string {
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+
+// OnConflict ignores the target as mysql resolves conflicts on any unique key.
+// Conflicting rows are left untouched by assigning a target column to itself, which requires a target
+func (mysql) OnConflict(target, update Columns) string {
+	var assignments []string
+	for _, col := range update {
+		assignments = append(assignments, fmt.Sprintf("%s = VALUES(%s)", col, col))
+	}
+
+	if len(assignments) == 0 && len(target) > 0 {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", target[0], target[0]))
+	}
+
+	if len(assignments) == 0 {
+		return ""
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+func (mysql) TypeName(typ string) string {
+	switch typ {
+	case "SERIAL":
+		return "INTEGER AUTO_INCREMENT"
+	case "TIMESTAMPTZ":
+		return "TIMESTAMP"
+	case "JSONB":
+		return "JSON"
+	case "INTERVAL":
+		return "TIME"
+	default:
+		return typ
+	}
+}
+
+// onConflict formats the ON CONFLICT clause shared by postgres and sqlite
+func onConflict(target, update Columns) string {
+	clause := "ON CONFLICT"
+	if len(target) > 0 {
+		clause = fmt.Sprintf("%s (%s)", clause, target.List())
+	}
+
+	if len(update) == 0 {
+		return clause + " DO NOTHING"
+	}
+
+	var assignments []string
+	for _, col := range update {
+		assignments = append(assignments, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+	}
+
+	return fmt.Sprintf("%s DO UPDATE SET %s", clause, strings.Join(assignments, ", "))
+}
+
+// quote wraps each part of a dotted identifier in q, escaping any occurrences of q by doubling them
+func quote(ident string, q byte) string {
+	parts := strings.Split(ident, ".")
+	for i, part := range parts {
+		escaped := strings.ReplaceAll(part, string(q), string(q)+string(q))
+		parts[i] = string(q) + escaped + string(q)
+	}
+	return strings.Join(parts, ".")
+}
+
+// limitOffset formats a LIMIT / OFFSET clause.
+// Some databases do not accept an OFFSET without a LIMIT, in which case unlimited is used as the limit.
+func limitOffset(limit, offset int, unlimited string) string {
+	var parts []string
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", limit))
+	} else if offset > 0 && unlimited != "" {
+		parts = append(parts, "LIMIT "+unlimited)
+	}
+
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", offset))
+	}
+
+	return strings.Join(parts, " ")
+}
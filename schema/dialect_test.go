@@ -0,0 +1,74 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/cristosal/orm/schema"
+)
+
+func TestDialectPlaceholders(t *testing.T) {
+	cols := schema.Columns{"name", "email"}
+
+	tt := []struct {
+		dialect     schema.Dialect
+		values      string
+		assignments string
+	}{
+		{schema.Postgres, "$2, $3", "name = $2, email = $3"},
+		{schema.SQLite, "?2, ?3", "name = ?2, email = ?3"},
+		{schema.MySQL, "?, ?", "name = ?, email = ?"},
+	}
+
+	for _, tc := range tt {
+		if got := cols.ValueListFor(tc.dialect, 2); got != tc.values {
+			t.Fatalf("%s: expected %s got %s", tc.dialect.Name(), tc.values, got)
+		}
+
+		if got := cols.AssignmentListFor(tc.dialect, 2); got != tc.assignments {
+			t.Fatalf("%s: expected %s got %s", tc.dialect.Name(), tc.assignments, got)
+		}
+	}
+}
+
+func TestDialectQuote(t *testing.T) {
+	tt := [][]string{
+		{schema.Postgres.Quote("public.user"), `"public"."user"`},
+		{schema.SQLite.Quote(`we"ird`), `"we""ird"`},
+		{schema.MySQL.Quote("user"), "`user`"},
+		{schema.Columns{"id", "order"}.Quoted(schema.Postgres).List(), `"id", "order"`},
+	}
+
+	for i, tc := range tt {
+		if tc[0] != tc[1] {
+			t.Fatalf("test case %d failed:\nexpected: %s\ngot: %s", i, tc[1], tc[0])
+		}
+	}
+}
+
+func TestDialectLimitOffset(t *testing.T) {
+	tt := [][]string{
+		{schema.Postgres.LimitOffset(10, 20), "LIMIT 10 OFFSET 20"},
+		{schema.Postgres.LimitOffset(10, 0), "LIMIT 10"},
+		{schema.Postgres.LimitOffset(0, 20), "OFFSET 20"},
+		{schema.SQLite.LimitOffset(0, 20), "LIMIT -1 OFFSET 20"},
+		{schema.MySQL.LimitOffset(0, 20), "LIMIT 18446744073709551615 OFFSET 20"},
+	}
+
+	for i, tc := range tt {
+		if tc[0] != tc[1] {
+			t.Fatalf("test case %d failed:\nexpected: %s\ngot: %s", i, tc[1], tc[0])
+		}
+	}
+}
+
+func TestCreateTableWithDialect(t *testing.T) {
+	got := schema.CreateTable("users", func(t *schema.TableDefinition) {
+		t.Serial("id").PrimaryKey()
+		t.TimestampTZ("created_at").NotNull()
+	}).WithDialect(schema.SQLite).String()
+
+	expected := "CREATE TABLE users (id INTEGER PRIMARY KEY, created_at TIMESTAMP NOT NULL)"
+	if got != expected {
+		t.Fatalf("expected: %s\ngot: %s", expected, got)
+	}
+}
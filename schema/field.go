@@ -7,6 +7,11 @@ type FieldMapping struct {
 	Index        int            // Index of the field within a struct
 	IsReadOnly   bool           // Is only for select queries
 	IsPrimaryKey bool           // Is a pk field
+	IsVersion    bool           // Is a version field used for optimistic locking
+	IsSoftDelete bool           // Is a deletion timestamp which marks rows as removed
+	IsAutoCreate bool           // Is a creation timestamp set when the row is added
+	IsAutoUpdate bool           // Is a modification timestamp set whenever the row is added or updated
+	IsNullZero   bool           // Is scanned from NULL as its zero value and written as NULL when zero
 	ForeignKey   *ForeignKey    // Foreign key meta data
 	Schema       *StructMapping // Embeded schema
 }
@@ -22,39 +27,63 @@ func (f *FieldMapping) HasSchema() bool {
 	return f.Schema != nil
 }
 
-// IsWriteable is true when the fields value can be included in an insert or update statement
+// IsWriteable is true when the fields value can be included in an insert statement.
+// Primary keys are only writeable when they are not generated by the database, such as the columns of a composite key.
 func (f *FieldMapping) IsWriteable() bool {
-	return !f.IsReadOnly && !f.IsPrimaryKey
+	return !f.IsReadOnly
+}
+
+// IsUpdateable is true when the fields value can be set by an update statement.
+// Primary keys identify the updated row and are never set, nor are soft delete timestamps which only change on removal.
+func (f *FieldMapping) IsUpdateable() bool {
+	return f.IsWriteable() && !f.IsPrimaryKey && !f.IsSoftDelete
 }
 
 type FieldMappings []FieldMapping
 
 // Find recursively searches for the field that matches the predicate and returns the field along with the index path
 func (fields FieldMappings) Find(predicate func(*FieldMapping) bool) (*FieldMapping, []int, error) {
-	var index []int
-
 	for _, field := range fields {
 		if predicate(&field) {
-			index = append(index, field.Index)
-			return &field, index, nil
+			return &field, []int{field.Index}, nil
 		}
 
 		// recursively look through embeded schemas
 		if field.HasSchema() {
-			index = append(index, field.Index)
 			f, indexes, err := field.Schema.Fields.Find(predicate)
 			if err != nil {
-				break
+				continue
 			}
 
-			index = append(index, indexes...)
-			return f, index, nil
+			return f, append([]int{field.Index}, indexes...), nil
 		}
 	}
 
 	return nil, nil, ErrFieldNotFound
 }
 
+// FindAll recursively searches for all fields that match the predicate and returns them along with their index paths
+func (fields FieldMappings) FindAll(predicate func(*FieldMapping) bool) (found FieldMappings, indexes [][]int) {
+	for _, field := range fields {
+		if predicate(&field) {
+			found = append(found, field)
+			indexes = append(indexes, []int{field.Index})
+			continue
+		}
+
+		// recursively look through embeded schemas
+		if field.HasSchema() {
+			fs, idxs := field.Schema.Fields.FindAll(predicate)
+			for i := range fs {
+				found = append(found, fs[i])
+				indexes = append(indexes, append([]int{field.Index}, idxs[i]...))
+			}
+		}
+	}
+
+	return
+}
+
 // FindByColumn returns the field and index which has the given column name
 func (fields FieldMappings) FindByColumn(col string) (*FieldMapping, []int, error) {
 	return fields.Find(func(f *FieldMapping) bool {
@@ -69,6 +98,20 @@ func (fields FieldMappings) FindPK() (*FieldMapping, []int, error) {
 	})
 }
 
+// FindPKs returns all primary key fields along with their index paths.
+// Tables with a composite primary key have more than one.
+func (fields FieldMappings) FindPKs() (FieldMappings, [][]int, error) {
+	found, indexes := fields.FindAll(func(f *FieldMapping) bool {
+		return f.IsPrimaryKey
+	})
+
+	if len(found) == 0 {
+		return nil, nil, ErrFieldNotFound
+	}
+
+	return found, indexes, nil
+}
+
 // FindFKS are fields representing foreign keys
 func (fields FieldMappings) FindFKS() FieldMappings {
 	info := []FieldMapping{}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// NullZero treats every field of a basic type or time.Time as if it had the nullzero tag option.
+// Primary keys and version fields are excluded. Zero values are then written as NULL, so columns which hold zeros must be nullable.
+var NullZero = false
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// ScanDest returns the destination scanned into for the field value v, which must be addressable.
+// Fields which are nullzero are wrapped in a scanner which sets the zero value for NULL, others are scanned through their address.
+func ScanDest(f *FieldMapping, v reflect.Value) (any, error) {
+	if !f.nullZero(v.Type()) {
+		if f.IsNullZero {
+			return nil, fmt.Errorf("%w: nullzero is not supported for %s of type %s", ErrInvalidType, f.Name, v.Type())
+		}
+
+		return v.Addr().Interface(), nil
+	}
+
+	return &nullZeroScanner{v}, nil
+}
+
+// nullZero is true when NULL is mapped to the zero value of the field of type t
+func (f *FieldMapping) nullZero(t reflect.Type) bool {
+	if !f.IsNullZero && (!NullZero || f.IsPrimaryKey || f.IsVersion) {
+		return false
+	}
+
+	// scanners handle NULL themselves
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return t == timeType
+	}
+}
+
+// nullZeroScanner scans into the field it wraps, setting its zero value for NULL.
+// Other values are converted by the sql.Null types, which follow the conversion rules of database/sql.
+type nullZeroScanner struct {
+	field reflect.Value
+}
+
+func (s *nullZeroScanner) Scan(src any) error {
+	if src == nil {
+		s.field.SetZero()
+		return nil
+	}
+
+	f := s.field
+	switch f.Kind() {
+	case reflect.String:
+		var n sql.NullString
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		f.SetString(n.String)
+	case reflect.Bool:
+		var n sql.NullBool
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		f.SetBool(n.Bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n sql.NullInt64
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		if f.OverflowInt(n.Int64) {
+			return fmt.Errorf("%w: %d overflows %s", ErrInvalidType, n.Int64, f.Type())
+		}
+
+		f.SetInt(n.Int64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n sql.NullInt64
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		if n.Int64 < 0 || f.OverflowUint(uint64(n.Int64)) {
+			return fmt.Errorf("%w: %d overflows %s", ErrInvalidType, n.Int64, f.Type())
+		}
+
+		f.SetUint(uint64(n.Int64))
+	case reflect.Float32, reflect.Float64:
+		var n sql.NullFloat64
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		if f.OverflowFloat(n.Float64) {
+			return fmt.Errorf("%w: %g overflows %s", ErrInvalidType, n.Float64, f.Type())
+		}
+
+		f.SetFloat(n.Float64)
+	default:
+		var n sql.NullTime
+		if err := n.Scan(src); err != nil {
+			return err
+		}
+
+		f.Set(reflect.ValueOf(n.Time))
+	}
+
+	return nil
+}
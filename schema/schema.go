@@ -62,8 +62,25 @@ func GetMapping(v any) (mapping *StructMapping, val reflect.Value, err error) {
 		return mapping, val, nil
 	}
 
-	// parse the mapping
-	mapping = &StructMapping{
+	mapping = parse(typ, val, table)
+	SaveMapping(table, mapping)
+	return
+}
+
+// ParseMapping returns the mapping of v without consulting or filling the cache.
+// It suits anonymous structs, which all share the empty table name.
+func ParseMapping(v any) (*StructMapping, reflect.Value, error) {
+	typ, val, err := Reflect(v)
+	if err != nil {
+		return nil, val, err
+	}
+
+	return parse(typ, val, snakecase(typ.Name())), val, nil
+}
+
+// parse builds the mapping of the struct type typ, whose value is val
+func parse(typ reflect.Type, val reflect.Value, table string) *StructMapping {
+	mapping := &StructMapping{
 		Table: table,
 		Type:  typ,
 	}
@@ -140,6 +157,18 @@ func GetMapping(v any) (mapping *StructMapping, val reflect.Value, err error) {
 				// TODO: add other cases for db tags here
 				case "ro", "readonly":
 					info.IsReadOnly = true
+				case "pk", "primarykey":
+					info.IsPrimaryKey = true
+				case "version":
+					info.IsVersion = true
+				case "softdelete":
+					info.IsSoftDelete = true
+				case "autocreate":
+					info.IsAutoCreate = true
+				case "autoupdate":
+					info.IsAutoUpdate = true
+				case "nullzero":
+					info.IsNullZero = true
 				}
 			}
 		}
@@ -147,8 +176,7 @@ func GetMapping(v any) (mapping *StructMapping, val reflect.Value, err error) {
 		mapping.Fields = append(mapping.Fields, info)
 	}
 
-	SaveMapping(table, mapping)
-	return
+	return mapping
 }
 
 // MustGet panics if Get fails. See Get for further information
@@ -167,6 +195,7 @@ func ClearCache() {
 }
 
 // Addrs returns all scannable values from a given struct.
+// Nullzero fields are wrapped in scanners, see ScanDest.
 func Addrs(v interface{}) (values []any, err error) {
 	mapping, sv, err := GetMapping(v)
 	if err != nil {
@@ -186,13 +215,19 @@ func Addrs(v interface{}) (values []any, err error) {
 			continue
 		}
 
-		values = append(values, v.Addr().Interface())
+		dest, err := ScanDest(&f, v)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, dest)
 	}
 
 	return values, nil
 }
 
-// Values returns the values from struct fields not marked as readonly
+// Values returns the values from struct fields not marked as readonly.
+// The zero values of nullzero fields are returned as nil
 func Values(v interface{}) (values []any, err error) {
 	sch, sv, err := GetMapping(v)
 	if err != nil {
@@ -213,6 +248,11 @@ func Values(v interface{}) (values []any, err error) {
 			continue
 		}
 
+		if field.nullZero(v.Type()) && v.IsZero() {
+			values = append(values, nil)
+			continue
+		}
+
 		switch v.Kind() {
 		case reflect.Pointer,
 			reflect.Map,
@@ -1,7 +1,10 @@
 package schema_test
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/cristosal/orm/schema"
 )
@@ -60,3 +63,119 @@ func TestInferStructBase(t *testing.T) {
 		}
 	}
 }
+
+func TestFindPKs(t *testing.T) {
+	type Tenant struct {
+		TenantID int64 `db:"tenant_id,pk"`
+	}
+
+	type Membership struct {
+		Tenant
+		UserID int64 `db:"user_id,pk"`
+		Role   string
+	}
+
+	fields, indexes, err := schema.MustGet(&Membership{}).Fields.FindPKs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cols := fields.Columns().List(); cols != "tenant_id, user_id" {
+		t.Fatalf("expected tenant_id, user_id got %s", cols)
+	}
+
+	expected := [][]int{{0, 0}, {1}}
+	for i := range expected {
+		for j := range expected[i] {
+			if indexes[i][j] != expected[i][j] {
+				t.Fatalf("expected index path %v got %v", expected[i], indexes[i])
+			}
+		}
+	}
+
+	// fields after an embeded struct are still found
+	if _, index, err := schema.MustGet(&Membership{}).Fields.FindByColumn("role"); err != nil || index[0] != 2 {
+		t.Fatalf("expected role at index 2, got %v %v", index, err)
+	}
+}
+
+func TestParseMappingAnonymous(t *testing.T) {
+	a, _, err := schema.ParseMapping(struct{ Name string }{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _, err := schema.ParseMapping(struct {
+		Email string `db:"email_address"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cols := a.Fields.Columns().List(); cols != "name" {
+		t.Fatalf("expected name got %s", cols)
+	}
+
+	if cols := b.Fields.Columns().List(); cols != "email_address" {
+		t.Fatalf("expected anonymous structs not to share a mapping, got %s", cols)
+	}
+
+	if schema.Lookup("") != nil {
+		t.Fatal("expected parsed mappings not to be cached")
+	}
+}
+
+func TestNullZero(t *testing.T) {
+	type Contact struct {
+		ID      int64
+		Phone   string    `db:"phone,nullzero"`
+		Age     int8      `db:"age,nullzero"`
+		Seen    time.Time `db:"seen,nullzero"`
+		Country string
+	}
+
+	c := Contact{ID: 1, Phone: "555", Age: 30, Seen: time.Now(), Country: "NZ"}
+	addrs, err := schema.Addrs(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{1, 2, 3} {
+		if err := addrs[i].(sql.Scanner).Scan(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := addrs[2].(sql.Scanner).Scan([]byte("42")); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Phone != "" || !c.Seen.IsZero() || c.Age != 42 {
+		t.Fatalf("expected NULL to scan as zero values, got %+v", c)
+	}
+
+	if err := addrs[2].(sql.Scanner).Scan(int64(300)); !errors.Is(err, schema.ErrInvalidType) {
+		t.Fatalf("expected overflow to be reported, got %v", err)
+	}
+
+	values, err := schema.Values(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values[0] != nil || values[1] != int8(42) || values[2] != nil || values[3] != "NZ" {
+		t.Fatalf("expected zero nullzero values to be written as NULL, got %v", values)
+	}
+
+	schema.NullZero = true
+	defer func() { schema.NullZero = false }()
+
+	c.Country = ""
+	if values, _ := schema.Values(&c); values[3] != nil {
+		t.Fatalf("expected global mode to write zero values as NULL, got %v", values)
+	}
+
+	if addrs, _ := schema.Addrs(&c); addrs[0] != &c.ID {
+		t.Fatal("expected primary keys to be scanned through their address in global mode")
+	}
+}
@@ -22,12 +22,24 @@ type CreateTableAction struct {
 	ifNotExists     bool
 	tableName       string
 	tableDefinition TableDefinition
+	dialect         Dialect
+}
+
+// WithDialect sets the dialect used to translate column types. Defaults to Postgres
+func (action *CreateTableAction) WithDialect(d Dialect) *CreateTableAction {
+	action.dialect = d
+	return action
 }
 
 func (action *CreateTableAction) String() string {
+	d := action.dialect
+	if d == nil {
+		d = Postgres
+	}
+
 	var lines []string
 	for _, cd := range action.tableDefinition.columns {
-		lines = append(lines, cd.String())
+		lines = append(lines, cd.format(d))
 	}
 
 	for _, fk := range action.tableDefinition.foreignKeys {
@@ -67,13 +79,18 @@ type ColumnDefinition struct {
 }
 
 func (cd *ColumnDefinition) String() string {
+	return cd.format(Postgres)
+}
+
+// format returns the column definition with its type translated by the given dialect
+func (cd *ColumnDefinition) format(d Dialect) string {
 	parts := []string{cd.name}
 
 	switch cd.typ {
 	case "VARCHAR", "CHAR":
 		parts = append(parts, fmt.Sprintf("%s(%d)", cd.typ, cd.length))
 	default:
-		parts = append(parts, cd.typ)
+		parts = append(parts, d.TypeName(cd.typ))
 	}
 
 	if cd.primaryKey {
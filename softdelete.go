@@ -0,0 +1,149 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// deletedScope determines which soft deleted rows are visible to a query
+type deletedScope int
+
+const (
+	excludeDeleted deletedScope = iota
+	includeDeleted
+	onlyDeleted
+)
+
+type deletedScopeKey struct{}
+
+// WithDeleted returns a context under which Get, List, Count and Paginate include soft deleted rows
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, includeDeleted)
+}
+
+// OnlyDeleted returns a context under which Get, List, Count and Paginate return only soft deleted rows
+func OnlyDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, onlyDeleted)
+}
+
+// scopeOf returns the deleted scope of ctx, excluding deleted rows by default
+func scopeOf(ctx context.Context) deletedScope {
+	scope, _ := ctx.Value(deletedScopeKey{}).(deletedScope)
+	return scope
+}
+
+// findSoftDelete returns the field tagged as the deletion timestamp of the mapping along with its index path
+func findSoftDelete(mapping *schema.StructMapping) (*schema.FieldMapping, []int, error) {
+	return mapping.Fields.Find(func(f *schema.FieldMapping) bool {
+		return f.IsSoftDelete
+	})
+}
+
+// fromClause returns the table expression selected from for the mapping, quoted for the dialect.
+// Soft deleted rows are filtered in a subquery aliased as the table itself,
+// so that the sql argument of the caller applies unchanged.
+func fromClause(ctx context.Context, d Dialect, mapping *schema.StructMapping) string {
+	table := d.Quote(mapping.Table)
+
+	field, _, err := findSoftDelete(mapping)
+	if err != nil {
+		return table
+	}
+
+	var cond string
+	switch scopeOf(ctx) {
+	case includeDeleted:
+		return table
+	case onlyDeleted:
+		cond = d.Quote(field.Column) + " IS NOT NULL"
+	default:
+		cond = d.Quote(field.Column) + " IS NULL"
+	}
+
+	// the alias can not be qualified by a schema
+	alias := d.Quote(mapping.Table[strings.LastIndex(mapping.Table, ".")+1:])
+	return fmt.Sprintf("(SELECT * FROM %s WHERE %s) AS %s", table, cond, alias)
+}
+
+// softRemoveSQL returns the statement marking the rows matched by the sql argument as deleted
+func softRemoveSQL(d Dialect, mapping *schema.StructMapping, field *schema.FieldMapping, sql string) string {
+	return strings.TrimSpace(fmt.Sprintf("UPDATE %s SET %s = CURRENT_TIMESTAMP %s", d.Quote(mapping.Table), d.Quote(field.Column), sql))
+}
+
+func (o *ORM) HardRemove(v any, sql any, args ...any) error {
+	return HardRemove(o.conn(), v, sql, args...)
+}
+
+func (o *ORM) HardRemoveContext(ctx context.Context, v any, sql any, args ...any) error {
+	return HardRemoveContext(ctx, o.conn(), v, sql, args...)
+}
+
+// HardRemove deletes the rows matched by the sql argument, even when v is soft deleted
+func HardRemove(db Executer, v any, sql any, args ...any) error {
+	return HardRemoveContext(context.Background(), executerContext(db), v, sql, args...)
+}
+
+// HardRemoveContext deletes the rows matched by the sql argument, even when v is soft deleted. See RemoveContext for details on the sql argument
+func HardRemoveContext(ctx context.Context, db ExecuterContext, v any, sql any, args ...any) error {
+	sch, _, err := schema.GetMapping(v)
+	if err != nil {
+		return err
+	}
+
+	s, args, err := whereOf(db, sql, args)
+	if err != nil {
+		return err
+	}
+
+	return withRemoveHooks(ctx, db, v, func() error {
+		ctx := withMapping(ctx, sch)
+		return hardRemove(ctx, db, sch, s, args...)
+	})
+}
+
+// hardRemove deletes the rows of the mapping matched by the sql argument
+func hardRemove(ctx context.Context, db ExecuterContext, mapping *schema.StructMapping, sql string, args ...any) error {
+	sqlstr := fmt.Sprintf("DELETE FROM %s %s", dialectOf(db).Quote(mapping.Table), sql)
+	return ExecContext(ctx, db, sqlstr, args...)
+}
+
+func (o *ORM) HardRemoveByID(v any) error {
+	return HardRemoveByID(o.conn(), v)
+}
+
+func (o *ORM) HardRemoveByIDContext(ctx context.Context, v any) error {
+	return HardRemoveByIDContext(ctx, o.conn(), v)
+}
+
+// HardRemoveByID deletes the row of v by its primary key, even when v is soft deleted
+func HardRemoveByID(db Executer, v any) error {
+	return HardRemoveByIDContext(context.Background(), executerContext(db), v)
+}
+
+// HardRemoveByIDContext deletes the row of v by its primary key, even when v is soft deleted
+func HardRemoveByIDContext(ctx context.Context, db ExecuterContext, v any) error {
+	sch, _, err := schema.GetMapping(v)
+	if err != nil {
+		return err
+	}
+
+	return withRemoveHooks(ctx, db, v, func() error {
+		ctx := withMapping(ctx, sch)
+		return hardRemoveByID(ctx, db, sch, v)
+	})
+}
+
+// hardRemoveByID deletes the row of v by its primary key
+func hardRemoveByID(ctx context.Context, db ExecuterContext, mapping *schema.StructMapping, v any) error {
+	d := dialectOf(db)
+	cond, vals, err := pkCondition(d, mapping, v, 1)
+	if err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s", d.Quote(mapping.Table), cond)
+	return ExecContext(ctx, db, sql, vals...)
+}
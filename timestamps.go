@@ -0,0 +1,82 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// NowFunc returns the time used to fill autocreate and autoupdate fields when the db argument does not carry a clock of its own.
+// See the WithClock option
+var NowFunc = time.Now
+
+// clocker is implemented by database handles which are bound to a clock
+type clocker interface {
+	Now() time.Time
+}
+
+// nowOf returns the current time according to the clock bound to db, falling back to NowFunc
+func nowOf(db any) time.Time {
+	if c, ok := db.(clocker); ok {
+		return c.Now()
+	}
+
+	return NowFunc()
+}
+
+// stampAdded fills the timestamps of v before it is inserted.
+// Autocreate fields which are already set are kept, autoupdate fields are always set.
+func stampAdded(mapping *schema.StructMapping, v any, now time.Time) error {
+	fields, indexes := mapping.Fields.FindAll(func(f *schema.FieldMapping) bool {
+		return f.IsAutoCreate || f.IsAutoUpdate
+	})
+
+	for i, f := range fields {
+		field := reflect.ValueOf(v).Elem().FieldByIndex(indexes[i])
+		if f.IsAutoCreate && !f.IsAutoUpdate && !field.IsZero() {
+			continue
+		}
+
+		if err := setTimestamp(field, now); err != nil {
+			return fmt.Errorf("%w: %s", err, f.Name)
+		}
+	}
+
+	return nil
+}
+
+// stampUpdated sets the autoupdate fields of v before it is updated
+func stampUpdated(mapping *schema.StructMapping, v any, now time.Time) error {
+	fields, indexes := mapping.Fields.FindAll(func(f *schema.FieldMapping) bool {
+		return f.IsAutoUpdate
+	})
+
+	for i, f := range fields {
+		if err := setTimestamp(reflect.ValueOf(v).Elem().FieldByIndex(indexes[i]), now); err != nil {
+			return fmt.Errorf("%w: %s", err, f.Name)
+		}
+	}
+
+	return nil
+}
+
+// setTimestamp assigns t to a time.Time, *time.Time or sql.Scanner field such as sql.NullTime
+func setTimestamp(field reflect.Value, t time.Time) error {
+	switch field.Interface().(type) {
+	case time.Time:
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case *time.Time:
+		field.Set(reflect.ValueOf(&t))
+		return nil
+	}
+
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(t)
+	}
+
+	return ErrInvalidType
+}
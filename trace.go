@@ -0,0 +1,82 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// SpanInfo describes the statement a span is started for, following the OpenTelemetry database conventions
+type SpanInfo struct {
+	System    string // db.system, the name of the dialect
+	Statement string // db.statement
+	Operation string // db.operation, the leading keyword of the statement such as SELECT
+	Table     string // Table of the mapping the statement was generated for. Empty for raw sql
+}
+
+// Name returns the span name, made of the operation and table when known
+func (info SpanInfo) Name() string {
+	if info.Table == "" {
+		return info.Operation
+	}
+
+	return info.Operation + " " + info.Table
+}
+
+// Tracer starts a span around every statement issued through an ORM.
+// See the otelorm module for an OpenTelemetry implementation
+type Tracer interface {
+	Start(ctx context.Context, info SpanInfo) (context.Context, Span)
+}
+
+// Span is ended once its statement has run, with the error of the statement if any
+type Span interface {
+	End(err error)
+}
+
+// WithTracer traces every statement issued through the ORM with t. See TraceInterceptor
+func WithTracer(t Tracer) Option {
+	return WithInterceptors(TraceInterceptor(t))
+}
+
+// TraceInterceptor returns an interceptor which wraps every statement in a span started by t.
+// The span context is passed down to the remaining interceptors and the database.
+func TraceInterceptor(t Tracer) Interceptor {
+	return func(ctx context.Context, stmt *Statement, next Handler) (Response, error) {
+		info := SpanInfo{
+			Statement: stmt.SQL,
+			Operation: operationOf(stmt.SQL),
+		}
+
+		if stmt.Dialect != nil {
+			info.System = stmt.Dialect.Name()
+		}
+
+		if stmt.Mapping != nil {
+			info.Table = stmt.Mapping.Table
+		}
+
+		ctx, span := t.Start(ctx, info)
+		res, err := next(ctx, stmt)
+
+		// no rows is an expected outcome rather than a failure of the statement
+		if errors.Is(err, sql.ErrNoRows) {
+			span.End(nil)
+		} else {
+			span.End(err)
+		}
+
+		return res, err
+	}
+}
+
+// operationOf returns the leading keyword of a statement in upper case
+func operationOf(sql string) string {
+	fields := strings.Fields(strings.TrimLeft(sql, "("))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
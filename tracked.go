@@ -0,0 +1,162 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// Tracked is an opt-in handle which remembers the values of a record as they were loaded from the database.
+// Save uses the snapshot to update only the columns which have changed since.
+type Tracked[T any] struct {
+	Value    *T
+	snapshot []any
+}
+
+// Track starts tracking changes to v from its current values
+func Track[T any](v *T) (*Tracked[T], error) {
+	t := &Tracked[T]{Value: v}
+	if err := t.Reset(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Reset replaces the snapshot with the current values of the record, discarding any changes
+func (t *Tracked[T]) Reset() error {
+	snapshot, err := takeSnapshot(t.Value)
+	if err != nil {
+		return err
+	}
+
+	t.snapshot = snapshot
+	return nil
+}
+
+// Changed returns the updateable columns whose values differ from the snapshot
+func (t *Tracked[T]) Changed() ([]string, error) {
+	mapping, _, err := schema.GetMapping(t.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := takeSnapshot(t.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for i, field := range mapping.Fields.Writeable() {
+		if field.IsUpdateable() && !reflect.DeepEqual(current[i], t.snapshot[i]) {
+			changed = append(changed, field.Column)
+		}
+	}
+
+	return changed, nil
+}
+
+// Save updates the changed columns of the record by its primary key.
+// No statement is executed when nothing has changed.
+func (t *Tracked[T]) Save(db Executer) error {
+	return t.SaveContext(context.Background(), executerContext(db))
+}
+
+// SaveContext updates the changed columns of the record by its primary key.
+// No statement is executed when nothing has changed.
+func (t *Tracked[T]) SaveContext(ctx context.Context, db ExecuterContext) error {
+	changed, err := t.Changed()
+	if err != nil {
+		return err
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := UpdateByIDContext(ctx, db, t.Value, Only(changed...)); err != nil {
+		return err
+	}
+
+	return t.Reset()
+}
+
+// GetTracked returns the first row encountered as a tracked record. See Get for details on the sql argument
+func GetTracked[T any](db Querier, sql any, args ...any) (*Tracked[T], error) {
+	return GetTrackedContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// GetTrackedContext returns the first row encountered as a tracked record. See Get for details on the sql argument
+func GetTrackedContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) (*Tracked[T], error) {
+	v := new(T)
+	if err := GetContext(ctx, db, v, sql, args...); err != nil {
+		return nil, err
+	}
+
+	return Track(v)
+}
+
+// ListTracked returns all rows as tracked records. See List for details on the sql argument
+func ListTracked[T any](db Querier, sql any, args ...any) ([]*Tracked[T], error) {
+	return ListTrackedContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// ListTrackedContext returns all rows as tracked records. See List for details on the sql argument
+func ListTrackedContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) ([]*Tracked[T], error) {
+	var items []T
+	if err := ListContext(ctx, db, &items, sql, args...); err != nil {
+		return nil, err
+	}
+
+	tracked := make([]*Tracked[T], len(items))
+	for i := range items {
+		t, err := Track(&items[i])
+		if err != nil {
+			return nil, err
+		}
+
+		tracked[i] = t
+	}
+
+	return tracked, nil
+}
+
+// takeSnapshot copies the writeable values of v so that later changes made in place are detected
+func takeSnapshot(v any) ([]any, error) {
+	values, err := schema.Values(v)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, val := range values {
+		values[i] = snapshotValue(val)
+	}
+
+	return values, nil
+}
+
+// snapshotValue copies the parts of a value which could be modified in place
+func snapshotValue(v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		if dv, err := valuer.Value(); err == nil {
+			return snapshotValue(dv)
+		}
+	}
+
+	if b, ok := v.([]byte); ok {
+		return append([]byte(nil), b...)
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		return snapshotValue(rv.Elem().Interface())
+	}
+
+	return v
+}
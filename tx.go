@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// txDB adapts a transaction to DB. Transactions can not be started from it, nested transactions use savepoints instead
+type txDB struct {
+	*sql.Tx
+}
+
+func (txDB) Begin() (*sql.Tx, error) {
+	return nil, ErrTxNotSupported
+}
+
+func (txDB) BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error) {
+	return nil, ErrTxNotSupported
+}
+
+// Tx runs fn within a transaction. See TxContext for details
+func (o *ORM) Tx(fn func(*ORM) error) error {
+	return o.TxContext(context.Background(), nil, fn)
+}
+
+// TxContext runs fn within a transaction started with opts, passing it an ORM bound to the transaction.
+// The transaction is committed when fn returns nil and rolled back when it returns an error or panics.
+// When the ORM is itself bound to a transaction, fn runs within a savepoint instead and opts are ignored.
+func (o *ORM) TxContext(ctx context.Context, opts *sql.TxOptions, fn func(*ORM) error) error {
+	if _, ok := o.DB.(txDB); ok {
+		return o.savepoint(ctx, fn)
+	}
+
+	tx, err := o.conn().BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	inner := *o
+	inner.DB = txDB{tx}
+	inner.txDepth = 1
+
+	return runTx(func() error { return fn(&inner) }, tx.Commit, tx.Rollback)
+}
+
+// savepoint runs fn within a savepoint of the transaction the ORM is bound to
+func (o *ORM) savepoint(ctx context.Context, fn func(*ORM) error) error {
+	name := fmt.Sprintf("sp_%d", o.txDepth)
+	if err := o.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	inner := *o
+	inner.txDepth++
+
+	release := func() error { return o.ExecContext(ctx, "RELEASE SAVEPOINT "+name) }
+	rollback := func() error { return o.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name) }
+	return runTx(func() error { return fn(&inner) }, release, rollback)
+}
+
+// runTx runs fn, calling commit when it succeeds and rollback when it returns an error or panics.
+// A panic is propagated once rollback has been called.
+func runTx(fn func() error, commit, rollback func() error) error {
+	done := false
+	defer func() {
+		if !done {
+			rollback()
+		}
+	}()
+
+	err := fn()
+	done = true
+
+	if err != nil {
+		if rerr := rollback(); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+
+		return err
+	}
+
+	return commit()
+}
@@ -0,0 +1,210 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// GetOf returns the first row encountered as a T. See Get for details on the sql argument
+func GetOf[T any](db Querier, sql any, args ...any) (T, error) {
+	return GetOfContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// GetOfContext returns the first row encountered as a T. See Get for details on the sql argument
+func GetOfContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) (T, error) {
+	var v T
+	err := GetContext(ctx, db, &v, sql, args...)
+	return v, err
+}
+
+// ListOf returns all rows as a slice of T. See List for details on the sql argument
+func ListOf[T any](db Querier, sql any, args ...any) ([]T, error) {
+	return ListOfContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// ListOfContext returns all rows as a slice of T. See List for details on the sql argument
+func ListOfContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) ([]T, error) {
+	var items []T
+	err := ListContext(ctx, db, &items, sql, args...)
+	return items, err
+}
+
+// Find returns the T with the given primary key. Composite keys are given in the order of their fields
+func Find[T any](db Querier, id ...any) (T, error) {
+	return FindContext[T](context.Background(), querierContext(db), id...)
+}
+
+// FindContext returns the T with the given primary key. Composite keys are given in the order of their fields
+func FindContext[T any](ctx context.Context, db QuerierContext, id ...any) (T, error) {
+	var v T
+	mapping, _, err := schema.GetMapping(&v)
+	if err != nil {
+		return v, err
+	}
+
+	if err := setPKs(mapping, &v, id); err != nil {
+		return v, err
+	}
+
+	err = GetByIDContext(ctx, db, &v)
+	return v, err
+}
+
+// CountOf returns the number of rows of T matched by the sql argument
+func CountOf[T any](db Querier, sql any, args ...any) (int64, error) {
+	return CountOfContext[T](context.Background(), querierContext(db), sql, args...)
+}
+
+// CountOfContext returns the number of rows of T matched by the sql argument
+func CountOfContext[T any](ctx context.Context, db QuerierContext, sql any, args ...any) (int64, error) {
+	var v T
+	return CountContext(ctx, db, &v, sql, args...)
+}
+
+// setPKs assigns ids to the primary key fields of v, converting them to the type of each field
+func setPKs(mapping *schema.StructMapping, v any, ids []any) error {
+	fields, indexes, err := mapping.Fields.FindPKs()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) != len(fields) {
+		return fmt.Errorf("%w: expected %d primary key values, got %d", ErrInvalidType, len(fields), len(ids))
+	}
+
+	for i, f := range fields {
+		field := reflect.ValueOf(v).Elem().FieldByIndex(indexes[i])
+		id := reflect.ValueOf(ids[i])
+
+		// integers convert to strings as runes, which is never the intended key
+		if !id.IsValid() || !id.Type().ConvertibleTo(field.Type()) || field.Kind() == reflect.String && id.Kind() != reflect.String || !fits(id, field) {
+			return fmt.Errorf("%w: %T can not be used as %s", ErrInvalidType, ids[i], f.Name)
+		}
+
+		field.Set(id.Convert(field.Type()))
+	}
+
+	return nil
+}
+
+// fits is true when id converts to the type of field without panicking or overflowing.
+// Slices only convert to arrays of the same length, and integers must be in range of the field
+func fits(id, field reflect.Value) bool {
+	switch {
+	case id.Kind() == reflect.Slice && field.Kind() == reflect.Array:
+		return id.Len() == field.Len()
+	case id.CanInt() && field.CanInt():
+		return !field.OverflowInt(id.Int())
+	case id.CanInt() && field.CanUint():
+		return id.Int() >= 0 && !field.OverflowUint(uint64(id.Int()))
+	case id.CanUint() && field.CanInt():
+		return id.Uint() <= math.MaxInt64 && !field.OverflowInt(int64(id.Uint()))
+	case id.CanUint() && field.CanUint():
+		return !field.OverflowUint(id.Uint())
+	default:
+		return true
+	}
+}
+
+// Repository binds the records of type T to a database.
+// The mapping of T is resolved once when the repository is created
+type Repository[T any] struct {
+	db      QuerierExecuterContext
+	mapping *schema.StructMapping
+}
+
+// NewRepository returns a Repository of T using db, which may be the Conn of an ORM.
+// An error is returned when T can not be mapped
+func NewRepository[T any](db QuerierExecuter) (*Repository[T], error) {
+	mapping, _, err := schema.GetMapping(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository[T]{db: querierExecuterContext(db), mapping: mapping}, nil
+}
+
+// Mapping returns the mapping of T
+func (r *Repository[T]) Mapping() *schema.StructMapping {
+	return r.mapping
+}
+
+// Get returns the first row encountered. See Get for details on the sql argument
+func (r *Repository[T]) Get(sql any, args ...any) (T, error) {
+	return r.GetContext(context.Background(), sql, args...)
+}
+
+// GetContext returns the first row encountered. See Get for details on the sql argument
+func (r *Repository[T]) GetContext(ctx context.Context, sql any, args ...any) (T, error) {
+	return GetOfContext[T](ctx, r.db, sql, args...)
+}
+
+// List returns all rows matched by the sql argument
+func (r *Repository[T]) List(sql any, args ...any) ([]T, error) {
+	return r.ListContext(context.Background(), sql, args...)
+}
+
+// ListContext returns all rows matched by the sql argument
+func (r *Repository[T]) ListContext(ctx context.Context, sql any, args ...any) ([]T, error) {
+	return ListOfContext[T](ctx, r.db, sql, args...)
+}
+
+// Find returns the record with the given primary key
+func (r *Repository[T]) Find(id ...any) (T, error) {
+	return r.FindContext(context.Background(), id...)
+}
+
+// FindContext returns the record with the given primary key
+func (r *Repository[T]) FindContext(ctx context.Context, id ...any) (T, error) {
+	var v T
+	if err := setPKs(r.mapping, &v, id); err != nil {
+		return v, err
+	}
+
+	err := GetByIDContext(ctx, r.db, &v)
+	return v, err
+}
+
+// Count returns the number of rows matched by the sql argument
+func (r *Repository[T]) Count(sql any, args ...any) (int64, error) {
+	return r.CountContext(context.Background(), sql, args...)
+}
+
+// CountContext returns the number of rows matched by the sql argument
+func (r *Repository[T]) CountContext(ctx context.Context, sql any, args ...any) (int64, error) {
+	return CountOfContext[T](ctx, r.db, sql, args...)
+}
+
+// Add inserts v, setting its generated id
+func (r *Repository[T]) Add(v *T) error {
+	return r.AddContext(context.Background(), v)
+}
+
+// AddContext inserts v, setting its generated id
+func (r *Repository[T]) AddContext(ctx context.Context, v *T) error {
+	return AddContext(ctx, r.db, v)
+}
+
+// Update sets the values of v by its primary key, restricted by filters
+func (r *Repository[T]) Update(v *T, filters ...ColumnFilter) error {
+	return r.UpdateContext(context.Background(), v, filters...)
+}
+
+// UpdateContext sets the values of v by its primary key, restricted by filters
+func (r *Repository[T]) UpdateContext(ctx context.Context, v *T, filters ...ColumnFilter) error {
+	return UpdateByIDContext(ctx, r.db, v, filters...)
+}
+
+// Remove removes v by its primary key
+func (r *Repository[T]) Remove(v *T) error {
+	return r.RemoveContext(context.Background(), v)
+}
+
+// RemoveContext removes v by its primary key
+func (r *Repository[T]) RemoveContext(ctx context.Context, v *T) error {
+	return RemoveByIDContext(ctx, r.db, v)
+}
@@ -0,0 +1,115 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// ErrNoColumns is returned when an update is left without any columns to set
+var ErrNoColumns = errors.New("no columns to update")
+
+// ColumnFilter restricts the writeable columns set by an update
+type ColumnFilter struct {
+	only    bool
+	columns []string
+}
+
+// Only restricts an update to the given columns
+func Only(cols ...string) ColumnFilter {
+	return ColumnFilter{only: true, columns: cols}
+}
+
+// Omit excludes the given columns from an update
+func Omit(cols ...string) ColumnFilter {
+	return ColumnFilter{columns: cols}
+}
+
+// includes is true when the filter lets col through
+func (f ColumnFilter) includes(col string) bool {
+	return slices.Contains(f.columns, col) == f.only
+}
+
+// validate asserts that every column of the filter is an updateable column of the mapping
+func (f ColumnFilter) validate(mapping *schema.StructMapping) error {
+	for _, col := range f.columns {
+		field, _, err := mapping.Fields.FindByColumn(col)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, col)
+		}
+
+		if !field.IsUpdateable() {
+			return fmt.Errorf("%w: %s is not updateable", schema.ErrFieldNotFound, col)
+		}
+	}
+
+	return nil
+}
+
+func (o *ORM) UpdateColumns(v any, cols ...string) error {
+	return UpdateColumns(o.conn(), v, cols...)
+}
+
+func (o *ORM) UpdateColumnsContext(ctx context.Context, v any, cols ...string) error {
+	return UpdateColumnsContext(ctx, o.conn(), v, cols...)
+}
+
+// UpdateColumns sets only the given columns of v by the primary key.
+// It is equivalent to calling UpdateByID with the Only filter.
+func UpdateColumns(db Executer, v any, cols ...string) error {
+	return UpdateByID(db, v, Only(cols...))
+}
+
+// UpdateColumnsContext sets only the given columns of v by the primary key.
+// It is equivalent to calling UpdateByIDContext with the Only filter.
+func UpdateColumnsContext(ctx context.Context, db ExecuterContext, v any, cols ...string) error {
+	return UpdateByIDContext(ctx, db, v, Only(cols...))
+}
+
+// updateValues returns the columns of v which are set by an update along with their values, restricted by filters.
+// Primary keys are excluded as they identify the updated rows, and soft delete timestamps as they are only set by a remove.
+// The version column is excluded as it is incremented by the update itself, as are autocreate columns which never change.
+// Autoupdate columns are set regardless of the filters.
+// ErrNoColumns is returned when the filters exclude every column.
+func updateValues(mapping *schema.StructMapping, v any, filters []ColumnFilter) (schema.Columns, []any, error) {
+	fields := mapping.Fields.Writeable()
+	cols := fields.Columns()
+	values, err := schema.Values(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, f := range filters {
+		if err := f.validate(mapping); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	version, _, _ := findVersion(mapping)
+
+	var (
+		selected schema.Columns
+		vals     []any
+	)
+
+	for i, col := range cols {
+		included := fields[i].IsUpdateable() && (version == nil || col != version.Column) && !fields[i].IsAutoCreate
+		for _, f := range filters {
+			included = included && f.includes(col)
+		}
+
+		if included || fields[i].IsAutoUpdate {
+			selected = append(selected, col)
+			vals = append(vals, values[i])
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, nil, ErrNoColumns
+	}
+
+	return selected, vals, nil
+}
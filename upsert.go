@@ -0,0 +1,256 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/cristosal/orm/schema"
+)
+
+// UpsertOptions configures how Upsert resolves conflicting rows
+type UpsertOptions struct {
+	Conflict  []string // Columns of the unique constraint to resolve conflicts on. Defaults to the primary key columns
+	Update    []string // Writeable columns set on conflict. Defaults to all writeable columns not in Conflict, except autocreate columns. Autoupdate columns are always set
+	DoNothing bool     // Leave conflicting rows untouched instead of updating them
+	Returning bool     // Scan the inserted or updated row back into v. Ignored by dialects without RETURNING support
+}
+
+// upsert contains the columns of an upsert statement for a given mapping
+type upsert struct {
+	mapping  *schema.StructMapping
+	extra    schema.Columns // conflict columns which are not writeable and must be inserted explicitly
+	columns  schema.Columns // all inserted columns, extra columns first
+	conflict schema.Columns
+	update   schema.Columns
+}
+
+func newUpsert(d Dialect, mapping *schema.StructMapping, opts *UpsertOptions) (*upsert, error) {
+	var (
+		u         = upsert{mapping: mapping}
+		fields    = mapping.Fields.Writeable()
+		writeable = fields.Columns()
+	)
+
+	if len(opts.Conflict) > 0 {
+		for _, col := range opts.Conflict {
+			if _, _, err := mapping.Fields.FindByColumn(col); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, col)
+			}
+		}
+
+		u.conflict = opts.Conflict
+	} else if pks, _, err := mapping.Fields.FindPKs(); err == nil {
+		u.conflict = pks.Columns()
+	} else if !opts.DoNothing {
+		return nil, fmt.Errorf("upsert requires a conflict target: %w", err)
+	} else if d.OnConflict(nil, nil) == "" {
+		return nil, fmt.Errorf("upsert requires a conflict target with %s: %w", d.Name(), err)
+	}
+
+	for _, col := range u.conflict {
+		if !slices.Contains(writeable, col) {
+			u.extra = append(u.extra, col)
+		}
+	}
+
+	u.columns = append(append(u.columns, u.extra...), writeable...)
+
+	if opts.DoNothing {
+		return &u, nil
+	}
+
+	if len(opts.Update) > 0 {
+		for _, col := range opts.Update {
+			if !slices.Contains(writeable, col) {
+				return nil, fmt.Errorf("%w: %s is not writeable", schema.ErrFieldNotFound, col)
+			}
+		}
+
+		u.update = slices.Clone(opts.Update)
+		for i, col := range writeable {
+			if fields[i].IsAutoUpdate && !slices.Contains(u.update, col) {
+				u.update = append(u.update, col)
+			}
+		}
+
+		return &u, nil
+	}
+
+	// the creation time of an existing row is kept
+	for i, col := range writeable {
+		if !slices.Contains(u.conflict, col) && !fields[i].IsAutoCreate {
+			u.update = append(u.update, col)
+		}
+	}
+
+	// there is nothing to update when every writeable column is part of the conflict target
+	if len(u.update) == 0 {
+		u.update = u.conflict
+	}
+
+	return &u, nil
+}
+
+// values returns the values of the inserted columns for v
+func (u *upsert) values(v any) ([]any, error) {
+	var values []any
+	for _, col := range u.extra {
+		_, index, err := u.mapping.Fields.FindByColumn(col)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, getValueAtIndex(v, index))
+	}
+
+	vals, err := schema.Values(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(values, vals...), nil
+}
+
+// sql returns the upsert statement for the given value lists
+func (u *upsert) sql(d Dialect, valueLists []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s",
+		d.Quote(u.mapping.Table), u.columns.Quoted(d).List(), strings.Join(valueLists, ", "), d.OnConflict(u.conflict.Quoted(d), u.update.Quoted(d)))
+}
+
+func (o *ORM) Upsert(v any, opts *UpsertOptions) error {
+	return Upsert(o.conn(), v, opts)
+}
+
+func (o *ORM) UpsertContext(ctx context.Context, v any, opts *UpsertOptions) error {
+	return UpsertContext(ctx, o.conn(), v, opts)
+}
+
+// Upsert inserts v, updating the existing row instead when it conflicts on the primary key or the columns given in opts
+func Upsert(db QuerierExecuter, v any, opts *UpsertOptions) error {
+	return UpsertContext(context.Background(), querierExecuterContext(db), v, opts)
+}
+
+// UpsertContext inserts v, updating the existing row instead when it conflicts on the primary key or the columns given in opts
+func UpsertContext(ctx context.Context, db QuerierExecuterContext, v any, opts *UpsertOptions) error {
+	if opts == nil {
+		opts = &UpsertOptions{}
+	}
+
+	mapping, _, err := schema.GetMapping(v)
+	if err != nil {
+		return err
+	}
+
+	ctx = withMapping(ctx, mapping)
+
+	d := dialectOf(db)
+	u, err := newUpsert(d, mapping, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := stampAdded(mapping, v, nowOf(db)); err != nil {
+		return err
+	}
+
+	values, err := u.values(v)
+	if err != nil {
+		return err
+	}
+
+	sqlstr := u.sql(d, []string{"(" + u.columns.ValueListFor(d, 1) + ")"})
+
+	if !opts.Returning || !d.Returning() {
+		return ExecContext(ctx, db, sqlstr, values...)
+	}
+
+	sqlstr = fmt.Sprintf("%s RETURNING %s", sqlstr, mapping.Fields.Columns().Quoted(d).List())
+	row := db.QueryRowContext(ctx, sqlstr, values...)
+	if row == nil {
+		return ErrNotFound
+	}
+
+	err = Scan(row, v)
+
+	// a conflicting row which was left untouched returns nothing
+	if opts.DoNothing && errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	return err
+}
+
+func (o *ORM) UpsertMany(v any, opts *UpsertOptions) error {
+	return UpsertMany(o.conn(), v, opts)
+}
+
+func (o *ORM) UpsertManyContext(ctx context.Context, v any, opts *UpsertOptions) error {
+	return UpsertManyContext(ctx, o.conn(), v, opts)
+}
+
+// UpsertMany upserts all records in the slice v with a single statement.
+// The Returning option is not supported. Postgres rejects statements which update the same row twice,
+// so v should not contain records which conflict with each other.
+func UpsertMany(db Executer, v any, opts *UpsertOptions) error {
+	return UpsertManyContext(context.Background(), executerContext(db), v, opts)
+}
+
+// UpsertManyContext upserts all records in the slice v with a single statement.
+// See UpsertMany for details
+func UpsertManyContext(ctx context.Context, db ExecuterContext, v any, opts *UpsertOptions) error {
+	if opts == nil {
+		opts = &UpsertOptions{}
+	}
+
+	slice := reflect.Indirect(reflect.ValueOf(v))
+	if slice.Kind() != reflect.Slice {
+		return ErrInvalidType
+	}
+
+	if slice.Len() == 0 {
+		return nil
+	}
+
+	mapping, _, err := schema.GetMapping(v)
+	if err != nil {
+		return err
+	}
+
+	ctx = withMapping(ctx, mapping)
+
+	d := dialectOf(db)
+	u, err := newUpsert(d, mapping, opts)
+	if err != nil {
+		return err
+	}
+
+	var (
+		now        = nowOf(db)
+		counter    = 1
+		valueLists []string
+		args       []any
+	)
+
+	for i := 0; i < slice.Len(); i++ {
+		record := slice.Index(i).Addr().Interface()
+		if err := stampAdded(mapping, record, now); err != nil {
+			return err
+		}
+
+		vals, err := u.values(record)
+		if err != nil {
+			return err
+		}
+
+		args = append(args, vals...)
+		valueLists = append(valueLists, "("+u.columns.ValueListFor(d, counter)+")")
+		counter += u.columns.Len()
+	}
+
+	return ExecContext(ctx, db, u.sql(d, valueLists), args...)
+}
@@ -0,0 +1,83 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/cristosal/orm/schema"
+)
+
+var (
+	// ErrStaleRecord is returned when an update of a versioned record affects no rows,
+	// meaning the record was modified or removed since it was read.
+	ErrStaleRecord = errors.New("stale record")
+
+	// ErrVersionClause is returned when the sql argument of an update on a versioned record is not a WHERE clause
+	ErrVersionClause = errors.New("versioned updates require the sql argument to be a WHERE clause")
+)
+
+// findVersion returns the field tagged as the version of the mapping along with its index path
+func findVersion(mapping *schema.StructMapping) (*schema.FieldMapping, []int, error) {
+	return mapping.Fields.Find(func(f *schema.FieldMapping) bool {
+		return f.IsVersion
+	})
+}
+
+// versionAssignment appends the increment of the quoted version column to an assignment list
+func versionAssignment(assignments string, column string) string {
+	increment := fmt.Sprintf("%s = %s + 1", column, column)
+	if assignments == "" {
+		return increment
+	}
+
+	return assignments + ", " + increment
+}
+
+// versionClause adds a condition on the quoted version column to a WHERE clause.
+// The existing condition is wrapped in parentheses so that its precedence is kept.
+func versionClause(sql string, column string, placeholder string) (string, error) {
+	cond := fmt.Sprintf("%s = %s", column, placeholder)
+	trimmed := strings.TrimSpace(sql)
+
+	if trimmed == "" {
+		return "WHERE " + cond, nil
+	}
+
+	if len(trimmed) < 6 || !strings.EqualFold(trimmed[:5], "WHERE") || !unicode.IsSpace(rune(trimmed[5])) {
+		return "", ErrVersionClause
+	}
+
+	return fmt.Sprintf("WHERE (%s) AND %s", strings.TrimSpace(trimmed[5:]), cond), nil
+}
+
+// execVersioned executes an update of a versioned record.
+// ErrStaleRecord is returned when no rows are affected, otherwise the version of v is incremented to match the database.
+func execVersioned(ctx context.Context, db ExecuterContext, v any, index []int, sql string, args ...any) error {
+	res, err := db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrStaleRecord
+	}
+
+	field := reflect.ValueOf(v).Elem().FieldByIndex(index)
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	}
+
+	return nil
+}